@@ -1,23 +1,26 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 
 	"github.com/dafuqqqyunglean/avito_tech/domain"
+	healthserv "github.com/dafuqqqyunglean/avito_tech/service/health"
 	prserv "github.com/dafuqqqyunglean/avito_tech/service/pr"
+	statsserv "github.com/dafuqqqyunglean/avito_tech/service/stats"
 	teamserv "github.com/dafuqqqyunglean/avito_tech/service/team"
 	userserv "github.com/dafuqqqyunglean/avito_tech/service/user"
 )
 
-func CreateTeam(ctx context.Context, service teamserv.Service) http.HandlerFunc {
+func CreateTeam(service teamserv.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
 		var req domain.TeamRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Error("failed to decode create team request", "error", err)
+			slog.ErrorContext(ctx, "failed to decode create team request", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 
 			return
@@ -33,7 +36,7 @@ func CreateTeam(ctx context.Context, service teamserv.Service) http.HandlerFunc
 			case errors.Is(err, domain.ErrBadRequest):
 				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 			default:
-				slog.Error("failed to create team", "error", err)
+				slog.ErrorContext(ctx, "failed to create team", "error", err)
 				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 			}
 
@@ -41,7 +44,7 @@ func CreateTeam(ctx context.Context, service teamserv.Service) http.HandlerFunc
 		}
 
 		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
-			slog.Error("failed to write response", "error", err)
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 
 			return
@@ -49,8 +52,10 @@ func CreateTeam(ctx context.Context, service teamserv.Service) http.HandlerFunc
 	}
 }
 
-func GetTeam(ctx context.Context, service teamserv.Service) http.HandlerFunc {
+func GetTeam(service teamserv.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
 		teamName := r.URL.Query().Get("team_name")
 
 		resp, err := service.GetTeam(ctx, teamName)
@@ -61,7 +66,109 @@ func GetTeam(ctx context.Context, service teamserv.Service) http.HandlerFunc {
 			case errors.Is(err, domain.ErrBadRequest):
 				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 			default:
-				slog.Error("failed to get team", "error", err)
+				slog.ErrorContext(ctx, "failed to get team", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func GetTeamSettings(service teamserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		teamName := r.URL.Query().Get("team_name")
+
+		resp, err := service.GetSettings(ctx, teamName)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			default:
+				slog.ErrorContext(ctx, "failed to get team settings", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func UpdateTeamSettings(service teamserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.TeamSettingsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode team settings request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.UpdateSettings(ctx, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			default:
+				slog.ErrorContext(ctx, "failed to update team settings", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func AddTeamMembers(service teamserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.AddMembersRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode add members request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.AddMembers(ctx, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			default:
+				slog.ErrorContext(ctx, "failed to add team members", "error", err)
 				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 			}
 
@@ -69,7 +176,7 @@ func GetTeam(ctx context.Context, service teamserv.Service) http.HandlerFunc {
 		}
 
 		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
-			slog.Error("failed to write response", "error", err)
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 
 			return
@@ -77,11 +184,186 @@ func GetTeam(ctx context.Context, service teamserv.Service) http.HandlerFunc {
 	}
 }
 
-func SetActive(ctx context.Context, service userserv.Service) http.HandlerFunc {
+func RemoveTeamMembers(service teamserv.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.RemoveMembersRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode remove members request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.RemoveMembers(ctx, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrTeamEmpty):
+				domain.NewErrorResponse(ctx, w, domain.ErrTeamEmpty, http.StatusConflict)
+			default:
+				slog.ErrorContext(ctx, "failed to remove team members", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func MoveTeamMember(service teamserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.MoveMemberRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode move member request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.MoveMember(ctx, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrTeamEmpty):
+				domain.NewErrorResponse(ctx, w, domain.ErrTeamEmpty, http.StatusConflict)
+			default:
+				slog.ErrorContext(ctx, "failed to move team member", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func ListTeams(service teamserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		resp, err := service.ListTeams(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to list teams", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func RenameTeam(service teamserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.RenameTeamRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode rename team request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.RenameTeam(ctx, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrTeamExists):
+				domain.NewErrorResponse(ctx, w, domain.ErrTeamExists, http.StatusBadRequest)
+			default:
+				slog.ErrorContext(ctx, "failed to rename team", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func DeleteTeam(service teamserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.DeleteTeamRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode delete team request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.DeleteTeam(ctx, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrTeamHasOpenPRs):
+				domain.NewErrorResponse(ctx, w, domain.ErrTeamHasOpenPRs, http.StatusConflict)
+			default:
+				slog.ErrorContext(ctx, "failed to delete team", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func SetActive(service userserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
 		var req domain.SetActiveRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Error("failed to decode set active request", "error", err)
+			slog.ErrorContext(ctx, "failed to decode set active request", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 
 			return
@@ -95,7 +377,7 @@ func SetActive(ctx context.Context, service userserv.Service) http.HandlerFunc {
 			case errors.Is(err, domain.ErrBadRequest):
 				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 			default:
-				slog.Error("failed to set user active status", "error", err)
+				slog.ErrorContext(ctx, "failed to set user active status", "error", err)
 				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 			}
 
@@ -103,7 +385,7 @@ func SetActive(ctx context.Context, service userserv.Service) http.HandlerFunc {
 		}
 
 		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
-			slog.Error("failed to write response", "error", err)
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 
 			return
@@ -111,8 +393,46 @@ func SetActive(ctx context.Context, service userserv.Service) http.HandlerFunc {
 	}
 }
 
-func GetReview(ctx context.Context, service userserv.Service) http.HandlerFunc {
+func DeactivateTeamMembers(service userserv.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.DeactivateUsersRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode deactivate users request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.DeactivateTeamMembers(ctx, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			default:
+				slog.ErrorContext(ctx, "failed to deactivate team members", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func GetReview(service userserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
 		userID := r.URL.Query().Get("user_id")
 
 		resp, err := service.GetReview(ctx, userID)
@@ -123,7 +443,7 @@ func GetReview(ctx context.Context, service userserv.Service) http.HandlerFunc {
 			case errors.Is(err, domain.ErrBadRequest):
 				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 			default:
-				slog.Error("failed to get user pull requests", "error", err)
+				slog.ErrorContext(ctx, "failed to get user pull requests", "error", err)
 				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 			}
 
@@ -131,7 +451,7 @@ func GetReview(ctx context.Context, service userserv.Service) http.HandlerFunc {
 		}
 
 		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
-			slog.Error("failed to write response", "error", err)
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 
 			return
@@ -139,11 +459,13 @@ func GetReview(ctx context.Context, service userserv.Service) http.HandlerFunc {
 	}
 }
 
-func CreatePullRequest(ctx context.Context, service prserv.Service) http.HandlerFunc {
+func CreatePullRequest(service prserv.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
 		var req domain.CreatePRRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Error("failed to decode create pr request", "error", err)
+			slog.ErrorContext(ctx, "failed to decode create pr request", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 
 			return
@@ -156,10 +478,14 @@ func CreatePullRequest(ctx context.Context, service prserv.Service) http.Handler
 				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
 			case errors.Is(err, domain.ErrBadRequest):
 				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrAmbiguousTeam):
+				domain.NewErrorResponse(ctx, w, domain.ErrAmbiguousTeam, http.StatusBadRequest)
 			case errors.Is(err, domain.ErrPRExists):
 				domain.NewErrorResponse(ctx, w, domain.ErrPRExists, http.StatusConflict)
+			case errors.Is(err, domain.ErrNotEnoughReviewers):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotEnoughReviewers, http.StatusConflict)
 			default:
-				slog.Error("failed create pull request", "error", err)
+				slog.ErrorContext(ctx, "failed create pull request", "error", err)
 				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 			}
 
@@ -167,7 +493,7 @@ func CreatePullRequest(ctx context.Context, service prserv.Service) http.Handler
 		}
 
 		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
-			slog.Error("failed to write response", "error", err)
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 
 			return
@@ -175,11 +501,13 @@ func CreatePullRequest(ctx context.Context, service prserv.Service) http.Handler
 	}
 }
 
-func SetMerged(ctx context.Context, service prserv.Service) http.HandlerFunc {
+func SetMerged(service prserv.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
 		var req domain.SetMergedRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Error("failed to decode set merged request", "error", err)
+			slog.ErrorContext(ctx, "failed to decode set merged request", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 
 			return
@@ -192,8 +520,10 @@ func SetMerged(ctx context.Context, service prserv.Service) http.HandlerFunc {
 				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
 			case errors.Is(err, domain.ErrBadRequest):
 				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrInvalidTransition):
+				domain.NewErrorResponse(ctx, w, domain.ErrInvalidTransition, http.StatusConflict)
 			default:
-				slog.Error("failed set merged pull request", "error", err)
+				slog.ErrorContext(ctx, "failed set merged pull request", "error", err)
 				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 			}
 
@@ -201,7 +531,7 @@ func SetMerged(ctx context.Context, service prserv.Service) http.HandlerFunc {
 		}
 
 		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
-			slog.Error("failed to write response", "error", err)
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 
 			return
@@ -209,11 +539,13 @@ func SetMerged(ctx context.Context, service prserv.Service) http.HandlerFunc {
 	}
 }
 
-func Reassign(ctx context.Context, service prserv.Service) http.HandlerFunc {
+func Reassign(service prserv.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
 		var req domain.ReassignRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			slog.Error("failed to decode reassign request", "error", err)
+			slog.ErrorContext(ctx, "failed to decode reassign request", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 
 			return
@@ -228,8 +560,164 @@ func Reassign(ctx context.Context, service prserv.Service) http.HandlerFunc {
 				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 			case errors.Is(err, domain.ErrPRMerged):
 				domain.NewErrorResponse(ctx, w, domain.ErrPRMerged, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrNoCandidate):
+				domain.NewErrorResponse(ctx, w, domain.ErrNoCandidate, http.StatusConflict)
+			case errors.Is(err, domain.ErrInvalidTransition):
+				domain.NewErrorResponse(ctx, w, domain.ErrInvalidTransition, http.StatusConflict)
+			default:
+				slog.ErrorContext(ctx, "failed to reassign reviewer", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func ClosePullRequest(service prserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.PRStatusRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode close pr request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.Close(ctx, req.PrID)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrInvalidTransition):
+				domain.NewErrorResponse(ctx, w, domain.ErrInvalidTransition, http.StatusConflict)
+			default:
+				slog.ErrorContext(ctx, "failed to close pull request", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func ReopenPullRequest(service prserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.PRStatusRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode reopen pr request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.Reopen(ctx, req.PrID)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrInvalidTransition):
+				domain.NewErrorResponse(ctx, w, domain.ErrInvalidTransition, http.StatusConflict)
+			case errors.Is(err, domain.ErrNotEnoughReviewers):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotEnoughReviewers, http.StatusConflict)
+			case errors.Is(err, domain.ErrPRHasNoTeam):
+				domain.NewErrorResponse(ctx, w, domain.ErrPRHasNoTeam, http.StatusConflict)
+			default:
+				slog.ErrorContext(ctx, "failed to reopen pull request", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func ReadyPullRequest(service prserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var req domain.PRStatusRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			slog.ErrorContext(ctx, "failed to decode ready pr request", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.Ready(ctx, req.PrID)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			case errors.Is(err, domain.ErrInvalidTransition):
+				domain.NewErrorResponse(ctx, w, domain.ErrInvalidTransition, http.StatusConflict)
+			case errors.Is(err, domain.ErrNotEnoughReviewers):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotEnoughReviewers, http.StatusConflict)
+			case errors.Is(err, domain.ErrPRHasNoTeam):
+				domain.NewErrorResponse(ctx, w, domain.ErrPRHasNoTeam, http.StatusConflict)
+			default:
+				slog.ErrorContext(ctx, "failed to mark ready pull request", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func GetPullRequest(service prserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		prID := r.URL.Query().Get("pull_request_id")
+
+		resp, err := service.Get(ctx, prID)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
 			default:
-				slog.Error("failed to reassign reviewer", "error", err)
+				slog.ErrorContext(ctx, "failed to get pull request", "error", err)
 				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 			}
 
@@ -237,7 +725,146 @@ func Reassign(ctx context.Context, service prserv.Service) http.HandlerFunc {
 		}
 
 		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
-			slog.Error("failed to write response", "error", err)
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func ListPullRequests(service prserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		filter, err := parsePRFilter(r.URL.Query())
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to parse list pr query", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.List(ctx, filter)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			default:
+				slog.ErrorContext(ctx, "failed to list pull requests", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func GetPullRequestHistory(service prserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		prID := r.URL.Query().Get("pull_request_id")
+
+		resp, err := service.GetHistory(ctx, prID)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrNotFound):
+				domain.NewErrorResponse(ctx, w, domain.ErrNotFound, http.StatusNotFound)
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			default:
+				slog.ErrorContext(ctx, "failed to get pull request history", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func GetAssignmentStats(service statsserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		from, err := parseTime(r.URL.Query(), "from")
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to parse stats query", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		to, err := parseTime(r.URL.Query(), "to")
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to parse stats query", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := service.GetAssignmentStats(ctx, from, to)
+		if err != nil {
+			switch {
+			case errors.Is(err, domain.ErrBadRequest):
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+			default:
+				slog.ErrorContext(ctx, "failed to get assignment stats", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+			}
+
+			return
+		}
+
+		if err = domain.WriteResponse(w, http.StatusOK, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func Liveness(service healthserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if err := domain.WriteResponse(w, http.StatusOK, service.Live(ctx)); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
+			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+
+			return
+		}
+	}
+}
+
+func Readiness(service healthserv.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		resp, ready := service.Ready(ctx)
+
+		statusCode := http.StatusOK
+		if !ready {
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		if err := domain.WriteResponse(w, statusCode, resp); err != nil {
+			slog.ErrorContext(ctx, "failed to write response", "error", err)
 			domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
 
 			return
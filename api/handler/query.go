@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+func parsePRFilter(query url.Values) (domain.PRFilter, error) {
+	filter := domain.PRFilter{
+		AuthorID:   query.Get("author_id"),
+		ReviewerID: query.Get("reviewer_id"),
+		TeamName:   query.Get("team_name"),
+		Status:     query.Get("status"),
+		Cursor:     query.Get("cursor"),
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return domain.PRFilter{}, fmt.Errorf("invalid limit: %w", err)
+		}
+
+		filter.Limit = n
+	}
+
+	timeParams := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"merged_from", &filter.MergedFrom},
+		{"merged_to", &filter.MergedTo},
+	}
+
+	for _, param := range timeParams {
+		t, err := parseTime(query, param.name)
+		if err != nil {
+			return domain.PRFilter{}, err
+		}
+
+		*param.dst = t
+	}
+
+	return filter, nil
+}
+
+// parseTime reads an optional RFC 3339 timestamp; a missing parameter yields nil.
+func parseTime(query url.Values, name string) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+
+	t = t.UTC()
+
+	return &t, nil
+}
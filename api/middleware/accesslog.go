@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// AccessLogMiddleware writes one log line per request. The request ID is added
+// by the logging handler from the request context.
+func AccessLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newResponseRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		slog.InfoContext(r.Context(), "request served",
+			"method", r.Method,
+			"route", routeTemplate(r),
+			"status", recorder.status,
+			"latency", time.Since(start),
+			"bytes", recorder.bytes)
+	})
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+const (
+	APIKeyHeader        = "X-API-Key"
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "Bearer "
+
+	maxPeekedBody = 1 << 20
+)
+
+// Authenticator resolves API keys and bearer tokens to callers.
+type Authenticator interface {
+	Authenticate(ctx context.Context, key string) (domain.Principal, error)
+	AuthenticateToken(ctx context.Context, token string) (domain.Principal, error)
+}
+
+// AuthMiddleware identifies the caller from an Authorization: Bearer token or,
+// failing that, an X-API-Key header and makes them the actor of the request.
+// Requests without credentials continue anonymously, so public routes such as
+// the probes keep working; RequireRole rejects them on protected routes.
+// Invalid credentials are rejected right away.
+func AuthMiddleware(authenticator Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			var principal domain.Principal
+			var err error
+
+			authorization := strings.TrimSpace(r.Header.Get(authorizationHeader))
+			key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+
+			switch {
+			case authorization != "":
+				token, found := strings.CutPrefix(authorization, bearerPrefix)
+				if !found {
+					domain.NewErrorResponse(ctx, w, domain.ErrUnauthorized, http.StatusUnauthorized)
+					return
+				}
+
+				principal, err = authenticator.AuthenticateToken(ctx, strings.TrimSpace(token))
+			case key != "":
+				principal, err = authenticator.Authenticate(ctx, key)
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if errors.Is(err, domain.ErrUnauthorized) {
+				domain.NewErrorResponse(ctx, w, domain.ErrUnauthorized, http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to authenticate request", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+				return
+			}
+
+			ctx = domain.WithPrincipal(ctx, principal)
+			ctx = domain.WithActor(ctx, principal.Name)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole lets only callers with one of roles reach next.
+func RequireRole(next http.HandlerFunc, roles ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		principal, ok := domain.PrincipalFromContext(ctx)
+		if !ok {
+			domain.NewErrorResponse(ctx, w, domain.ErrUnauthorized, http.StatusUnauthorized)
+			return
+		}
+
+		if !slices.Contains(roles, principal.Role) {
+			domain.NewErrorResponse(ctx, w, domain.ErrForbidden, http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// RequireTeamRole is RequireRole for routes acting on a single team. Team
+// maintainers additionally pass only when the request names their own team,
+// taken from the team_name query parameter of GET requests or the team_name
+// field of a JSON body otherwise.
+func RequireTeamRole(next http.HandlerFunc, roles ...string) http.HandlerFunc {
+	return RequireRole(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		principal, _ := domain.PrincipalFromContext(ctx)
+		if principal.Role == domain.RoleTeamMaintainer {
+			teamName, err := requestField(r, "team_name")
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to read request body", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+				return
+			}
+
+			if teamName != principal.TeamName {
+				domain.NewErrorResponse(ctx, w, domain.ErrForbidden, http.StatusForbidden)
+				return
+			}
+		}
+
+		next(w, r)
+	}, roles...)
+}
+
+// PRTeamResolver returns the name of the team a PR belongs to.
+type PRTeamResolver func(ctx context.Context, prID string) (string, error)
+
+// RequirePRTeamRole is RequireTeamRole for routes acting on an existing PR,
+// named by the pull_request_id field of the JSON body. Team maintainers pass
+// only for PRs of their own team. PRs that cannot be resolved are left to the
+// handler, which reports them as usual.
+func RequirePRTeamRole(next http.HandlerFunc, prTeam PRTeamResolver, roles ...string) http.HandlerFunc {
+	return RequireRole(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		principal, _ := domain.PrincipalFromContext(ctx)
+		if principal.Role == domain.RoleTeamMaintainer {
+			prID, err := requestField(r, "pull_request_id")
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to read request body", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrBadRequest, http.StatusBadRequest)
+				return
+			}
+
+			teamName, err := prTeam(ctx, prID)
+			switch {
+			case errors.Is(err, domain.ErrNotFound), errors.Is(err, domain.ErrBadRequest):
+			case err != nil:
+				slog.ErrorContext(ctx, "failed to resolve pr team", "error", err)
+				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+				return
+			case teamName != principal.TeamName:
+				domain.NewErrorResponse(ctx, w, domain.ErrForbidden, http.StatusForbidden)
+				return
+			}
+		}
+
+		next(w, r)
+	}, roles...)
+}
+
+// requestField reads a string field the request targets, from the query of
+// GET requests or the JSON body otherwise. The body is restored, so the
+// handler can decode it again.
+func requestField(r *http.Request, name string) (string, error) {
+	if r.Method == http.MethodGet {
+		return r.URL.Query().Get(name), nil
+	}
+
+	if r.Body == nil {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPeekedBody))
+	if err != nil {
+		return "", err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload map[string]json.RawMessage
+	// A malformed body is reported by the handler itself.
+	_ = json.Unmarshal(body, &payload)
+
+	var value string
+	_ = json.Unmarshal(payload[name], &value)
+
+	return value, nil
+}
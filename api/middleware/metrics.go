@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dafuqqqyunglean/avito_tech/metrics"
+	"github.com/gorilla/mux"
+)
+
+// MetricsMiddleware records request count and latency labelled with the
+// matched route template, so path parameters do not blow up cardinality.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newResponseRecorder(w)
+
+		next.ServeHTTP(recorder, r)
+
+		code := strconv.Itoa(recorder.status)
+		route := routeTemplate(r)
+		metrics.HTTPRequests.WithLabelValues(route, r.Method, code).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(route, r.Method, code).Observe(time.Since(start).Seconds())
+	})
+}
+
+type routeKey struct{}
+
+// RouteMiddleware matches the request against router up front and keeps the
+// route template in the request context. Middlewares wrapped around the router
+// run before mux picks a route, so this is how they label requests by route;
+// requests no route matches, 404s and 405s included, are labelled "unknown".
+func RouteMiddleware(router *mux.Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := "unknown"
+
+			var match mux.RouteMatch
+			if router.Match(r, &match) && match.MatchErr == nil && match.Route != nil {
+				if template, err := match.Route.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, route)))
+		})
+	}
+}
+
+func routeTemplate(r *http.Request) string {
+	if route, ok := r.Context().Value(routeKey{}).(string); ok {
+		return route
+	}
+
+	return "unknown"
+}
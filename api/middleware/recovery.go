@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"context"
 	"log/slog"
 	"net/http"
 	"runtime/debug"
@@ -9,12 +8,14 @@ import (
 	"github.com/dafuqqqyunglean/avito_tech/domain"
 )
 
-func RecoveryMiddleware(ctx context.Context, next http.Handler) http.Handler {
+func RecoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				slog.Error("panic occurred: %v\n%s", err, debug.Stack())
-				domain.NewErrorResponse(ctx, w, domain.ErrInternal, http.StatusInternalServerError)
+				slog.ErrorContext(r.Context(), "panic occurred",
+					"error", err,
+					"stack", string(debug.Stack()))
+				domain.NewErrorResponse(r.Context(), w, domain.ErrInternal, http.StatusInternalServerError)
 			}
 		}()
 
@@ -3,21 +3,19 @@ package api
 import (
 	"context"
 	"net/http"
-	"time"
 
 	"github.com/dafuqqqyunglean/avito_tech/api/handler"
 	"github.com/dafuqqqyunglean/avito_tech/api/middleware"
 	"github.com/dafuqqqyunglean/avito_tech/config"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	authserv "github.com/dafuqqqyunglean/avito_tech/service/auth"
+	healthserv "github.com/dafuqqqyunglean/avito_tech/service/health"
 	prserv "github.com/dafuqqqyunglean/avito_tech/service/pr"
+	statsserv "github.com/dafuqqqyunglean/avito_tech/service/stats"
 	teamserv "github.com/dafuqqqyunglean/avito_tech/service/team"
 	userserv "github.com/dafuqqqyunglean/avito_tech/service/user"
 	"github.com/gorilla/mux"
-)
-
-const (
-	maxHeaderBytes = 1 << 20
-	readTimeout    = 10 * time.Second
-	writeTimeout   = 10 * time.Second
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type Server struct {
@@ -25,17 +23,25 @@ type Server struct {
 	router     *mux.Router
 }
 
-func NewServer(ctx context.Context, config config.Config) *Server {
+func NewServer(config config.ServerConfig) *Server {
 	router := mux.NewRouter()
+	router.Use(middleware.RecoveryMiddleware)
 
-	wrappedRouter := middleware.RecoveryMiddleware(ctx, router)
+	// mux runs its own middlewares only on matched routes, so whatever has to
+	// see 404s and 405s too wraps the router from outside.
+	var wrappedRouter http.Handler = router
+	wrappedRouter = middleware.MetricsMiddleware(wrappedRouter)
+	wrappedRouter = middleware.AccessLogMiddleware(wrappedRouter)
+	wrappedRouter = middleware.TracingMiddleware(wrappedRouter)
+	wrappedRouter = middleware.RouteMiddleware(router)(wrappedRouter)
+	wrappedRouter = middleware.RequestContextMiddleware(config.RequestTimeout)(wrappedRouter)
 
 	return &Server{
 		httpServer: &http.Server{
-			Addr:           config.ServerPort,
-			MaxHeaderBytes: maxHeaderBytes,
-			ReadTimeout:    readTimeout,
-			WriteTimeout:   writeTimeout,
+			Addr:           config.Address(),
+			MaxHeaderBytes: config.MaxHeaderBytes,
+			ReadTimeout:    config.ReadTimeout,
+			WriteTimeout:   config.WriteTimeout,
 			Handler:        wrappedRouter,
 		},
 		router: router,
@@ -50,12 +56,50 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	return s.httpServer.Shutdown(ctx)
 }
 
-func (s *Server) HandleRoutes(ctx context.Context, teamService teamserv.Service, userService userserv.Service, prService prserv.Service) {
-	s.router.HandleFunc("/team/add", handler.CreateTeam(ctx, teamService)).Methods(http.MethodPost)
-	s.router.HandleFunc("/team/get", handler.GetTeam(ctx, teamService)).Methods(http.MethodGet)
-	s.router.HandleFunc("/users/setIsActive", handler.SetActive(ctx, userService)).Methods(http.MethodPost)
-	s.router.HandleFunc("/users/getReview", handler.GetReview(ctx, userService)).Methods(http.MethodGet)
-	s.router.HandleFunc("/pullRequest/create", handler.CreatePullRequest(ctx, prService)).Methods(http.MethodPost)
-	s.router.HandleFunc("/pullRequest/merge", handler.SetMerged(ctx, prService)).Methods(http.MethodPost)
-	s.router.HandleFunc("/pullRequest/reassign", handler.Reassign(ctx, prService)).Methods(http.MethodPost)
+// Roles allowed on the routes below. Reading is open to every role; merging is
+// reserved to admins and bots. Team maintainers edit only their own team and
+// its PRs; deactivating users is admin-only, since a user's activity is global
+// and may span other teams.
+var (
+	readers     = []string{domain.RoleAdmin, domain.RoleTeamMaintainer, domain.RoleBot, domain.RoleReader}
+	prEditors   = []string{domain.RoleAdmin, domain.RoleTeamMaintainer, domain.RoleBot}
+	mergers     = []string{domain.RoleAdmin, domain.RoleBot}
+	teamEditors = []string{domain.RoleAdmin, domain.RoleTeamMaintainer}
+	admins      = []string{domain.RoleAdmin}
+)
+
+func (s *Server) HandleRoutes(authService authserv.Service, teamService teamserv.Service, userService userserv.Service, prService prserv.Service, statsService statsserv.Service, healthService healthserv.Service) {
+	s.router.Use(middleware.AuthMiddleware(authService))
+
+	prTeam := func(ctx context.Context, prID string) (string, error) {
+		resp, err := prService.Get(ctx, prID)
+		return resp.PR.TeamName, err
+	}
+
+	s.router.HandleFunc("/healthz", handler.Liveness(healthService)).Methods(http.MethodGet)
+	s.router.HandleFunc("/readyz", handler.Readiness(healthService)).Methods(http.MethodGet)
+	s.router.HandleFunc("/team/add", middleware.RequireRole(handler.CreateTeam(teamService), admins...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/team/get", middleware.RequireRole(handler.GetTeam(teamService), readers...)).Methods(http.MethodGet)
+	s.router.HandleFunc("/team/list", middleware.RequireRole(handler.ListTeams(teamService), readers...)).Methods(http.MethodGet)
+	s.router.HandleFunc("/team/rename", middleware.RequireRole(handler.RenameTeam(teamService), admins...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/team/delete", middleware.RequireRole(handler.DeleteTeam(teamService), admins...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/team/settings", middleware.RequireRole(handler.GetTeamSettings(teamService), readers...)).Methods(http.MethodGet)
+	s.router.HandleFunc("/team/settings", middleware.RequireTeamRole(handler.UpdateTeamSettings(teamService), teamEditors...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/team/addMembers", middleware.RequireTeamRole(handler.AddTeamMembers(teamService), teamEditors...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/team/removeMembers", middleware.RequireTeamRole(handler.RemoveTeamMembers(teamService), teamEditors...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/team/moveMember", middleware.RequireRole(handler.MoveTeamMember(teamService), admins...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/team/deactivateUsers", middleware.RequireRole(handler.DeactivateTeamMembers(userService), admins...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/users/setIsActive", middleware.RequireRole(handler.SetActive(userService), admins...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/users/getReview", middleware.RequireRole(handler.GetReview(userService), readers...)).Methods(http.MethodGet)
+	s.router.HandleFunc("/pullRequest/create", middleware.RequireTeamRole(handler.CreatePullRequest(prService), prEditors...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/pullRequest/merge", middleware.RequireRole(handler.SetMerged(prService), mergers...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/pullRequest/reassign", middleware.RequirePRTeamRole(handler.Reassign(prService), prTeam, prEditors...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/pullRequest/get", middleware.RequireRole(handler.GetPullRequest(prService), readers...)).Methods(http.MethodGet)
+	s.router.HandleFunc("/pullRequest/history", middleware.RequireRole(handler.GetPullRequestHistory(prService), readers...)).Methods(http.MethodGet)
+	s.router.HandleFunc("/pullRequest/list", middleware.RequireRole(handler.ListPullRequests(prService), readers...)).Methods(http.MethodGet)
+	s.router.HandleFunc("/pullRequest/close", middleware.RequirePRTeamRole(handler.ClosePullRequest(prService), prTeam, prEditors...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/pullRequest/reopen", middleware.RequirePRTeamRole(handler.ReopenPullRequest(prService), prTeam, prEditors...)).Methods(http.MethodPost)
+	s.router.HandleFunc("/pullRequest/ready", middleware.RequirePRTeamRole(handler.ReadyPullRequest(prService), prTeam, prEditors...)).Methods(http.MethodPost)
+	s.router.Handle("/metrics", promhttp.Handler()).Methods(http.MethodGet)
+	s.router.HandleFunc("/stats/assignments", middleware.RequireRole(handler.GetAssignmentStats(statsService), readers...)).Methods(http.MethodGet)
 }
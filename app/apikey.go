@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dafuqqqyunglean/avito_tech/config"
+	"github.com/dafuqqqyunglean/avito_tech/logging"
+	"github.com/dafuqqqyunglean/avito_tech/service/auth"
+	"github.com/joho/godotenv"
+)
+
+const apiKeyUsage = `usage:
+  server apikey [server flags] create -name NAME -role admin|team-maintainer|reader|bot [-team TEAM]
+  server apikey [server flags] list
+  server apikey [server flags] revoke -id ID`
+
+const apiKeyCommandTimeout = 30 * time.Second
+
+// RunAPIKeys handles the apikey admin subcommand. The database is configured
+// the same way as for the server, from the config file, the environment and the
+// server flags given before the command. Logs go to stderr so that stdout holds
+// only the command's output.
+func (a *App) RunAPIKeys(args []string) error {
+	slog.SetDefault(slog.New(logging.NewContextHandler(slog.NewTextHandler(os.Stderr, nil))))
+
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	err := godotenv.Load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read .env file: %w", err)
+	}
+
+	cfg, args, err := config.LoadCommand(args)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if len(args) == 0 {
+		return errors.New(apiKeyUsage)
+	}
+
+	if cfg.Storage == config.StorageMemory {
+		return fmt.Errorf("api keys of %s storage exist only inside the running server", cfg.Storage)
+	}
+
+	repos, closeStorage, err := a.initStorage(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStorage()
+
+	service := auth.NewService(repos.apiKey, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyCommandTimeout)
+	defer cancel()
+
+	switch args[0] {
+	case "create":
+		return createAPIKey(ctx, service, args[1:])
+	case "list":
+		return listAPIKeys(ctx, service)
+	case "revoke":
+		return revokeAPIKey(ctx, service, args[1:])
+	default:
+		return fmt.Errorf("unknown apikey command %q\n%s", args[0], apiKeyUsage)
+	}
+}
+
+func createAPIKey(ctx context.Context, service auth.Service, args []string) error {
+	flags := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	name := flags.String("name", "", "key name, recorded as the actor of its changes")
+	role := flags.String("role", "", "admin, team-maintainer, reader or bot")
+	team := flags.String("team", "", "team of a team-maintainer key")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	key, apiKey, err := service.CreateKey(ctx, *name, *role, *team)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	fmt.Printf("id:   %d\nrole: %s\nkey:  %s\n", apiKey.ID, apiKey.Role, key)
+	fmt.Fprintln(os.Stderr, "store the key now, it cannot be shown again")
+
+	return nil
+}
+
+func listAPIKeys(ctx context.Context, service auth.Service) error {
+	keys, err := service.ListKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tROLE\tTEAM\tCREATED\tREVOKED")
+	for _, key := range keys {
+		revoked := "-"
+		if key.RevokedAt != nil {
+			revoked = key.RevokedAt.Format(time.DateTime)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Role, key.TeamName,
+			key.CreatedAt.Format(time.DateTime), revoked)
+	}
+
+	return w.Flush()
+}
+
+func revokeAPIKey(ctx context.Context, service auth.Service, args []string) error {
+	flags := flag.NewFlagSet("apikey revoke", flag.ContinueOnError)
+	id := flags.Int64("id", 0, "id of the key to revoke")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := service.RevokeKey(ctx, *id); err != nil {
+		return fmt.Errorf("failed to revoke api key %d: %w", *id, err)
+	}
+
+	fmt.Printf("api key %d revoked\n", *id)
+
+	return nil
+}
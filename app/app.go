@@ -3,115 +3,178 @@ package app
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/dafuqqqyunglean/avito_tech/api"
 	"github.com/dafuqqqyunglean/avito_tech/config"
-	prrepo "github.com/dafuqqqyunglean/avito_tech/database/pr"
-	teamrepo "github.com/dafuqqqyunglean/avito_tech/database/team"
-	userrepo "github.com/dafuqqqyunglean/avito_tech/database/user"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/logging"
+	"github.com/dafuqqqyunglean/avito_tech/service/auth"
+	"github.com/dafuqqqyunglean/avito_tech/service/health"
 	"github.com/dafuqqqyunglean/avito_tech/service/pr"
+	"github.com/dafuqqqyunglean/avito_tech/service/stats"
 	"github.com/dafuqqqyunglean/avito_tech/service/team"
 	"github.com/dafuqqqyunglean/avito_tech/service/user"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
 	"github.com/pressly/goose/v3"
 )
 
+const migrationsDir = "./migrations"
+
+// devAdminKeyName names the admin key minted on startup for in-memory storage.
+const devAdminKeyName = "dev-admin"
+
 type App struct{}
 
 func New() *App {
 	return &App{}
 }
 
-func (a *App) Run() error {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+// Run starts the service. args are the command line arguments without the
+// program name; they override the config file and environment.
+func (a *App) Run(args []string) error {
+	logger := slog.New(logging.NewContextHandler(slog.NewTextHandler(os.Stdout, nil)))
 	slog.SetDefault(logger)
 
+	// A .env file is a convenience for local runs; in production the
+	// environment is set directly.
 	err := godotenv.Load()
-	if err != nil {
-		slog.Warn(".env file not found", "error", err)
-		return fmt.Errorf(".env file not found: %w", err)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("failed to read .env file", "error", err)
+		return fmt.Errorf("failed to read .env file: %w", err)
 	}
 
-	config, err := config.NewConfig()
+	config, err := config.Load(args)
 	if err != nil {
-		slog.Warn("failed to read config", "error", err)
-		return fmt.Errorf("failed to read config: %w", err)
+		slog.Warn("invalid config", "error", err)
+		return fmt.Errorf("invalid config: %w", err)
 	}
 
-	pool, err := a.initDatabase(config)
+	shutdownTracing, err := tracing.Setup(context.Background(), config.Tracing)
 	if err != nil {
-		slog.Warn("failed to connect to db", "error", err)
-		return fmt.Errorf("failed to connect to db: %w", err)
+		slog.Warn("failed to set up tracing", "error", err)
+		return fmt.Errorf("failed to set up tracing: %w", err)
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), config.Server.ShutdownTimeout)
+		defer cancel()
+
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	}()
 
-	err = a.runMigrations(config)
+	repos, closeStorage, err := a.initStorage(config)
 	if err != nil {
-		slog.Warn("failed to apply migrations", "error", err)
-		return fmt.Errorf("failed to apply migrations: %w", err)
+		slog.Warn("failed to init storage", "error", err)
+		return fmt.Errorf("failed to init storage: %w", err)
 	}
+	defer closeStorage()
 
-	server := api.NewServer(ctx, config)
+	server := api.NewServer(config.Server)
 
-	a.initService(ctx, pool, server)
+	if err := a.initService(repos, server, config.JWT); err != nil {
+		slog.Warn("failed to init services", "error", err)
+		return fmt.Errorf("failed to init services: %w", err)
+	}
 
-	slog.Info("server running")
+	return a.serve(server, config.Server.ShutdownTimeout)
+}
+
+// serve runs the HTTP server until it fails or the process receives SIGINT or
+// SIGTERM. On a signal it stops accepting connections and waits up to timeout
+// for in-flight requests before returning.
+func (a *App) serve(server *api.Server, timeout time.Duration) error {
+	stop, stopCancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopCancel()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("server running")
+		serverErr <- server.Run()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
 
-	if err := server.Run(); err != nil {
 		slog.Error("error occured while running http server", "error", err)
 		return err
+	case <-stop.Done():
+		slog.Info("shutdown signal received, draining requests", "timeout", timeout)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shut down http server gracefully", "error", err)
+		return fmt.Errorf("failed to shut down http server: %w", err)
 	}
 
+	slog.Info("http server stopped")
+
 	return nil
 }
 
-func (a *App) initDatabase(config config.Config) (*pgxpool.Pool, error) {
-	slog.Info("connecting to DB", "conn", config.DBConnectionString)
+func (a *App) initDatabase(config config.DBConfig) (*pgxpool.Pool, error) {
+	slog.Info("connecting to DB", "host", config.Host, "port", config.Port, "name", config.Name)
+
+	poolConfig, err := pgxpool.ParseConfig(config.ConnectionString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB connection string: %w", err)
+	}
+	poolConfig.ConnConfig.Tracer = tracing.NewQueryTracer()
 
 	var pool *pgxpool.Pool
-	var err error
 
-	for i := 0; i < 10; i++ {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	for i := 0; i < config.ConnectAttempts; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), config.ConnectTimeout)
 
-		pool, err = pgxpool.New(ctx, config.DBConnectionString)
+		pool, err = pgxpool.NewWithConfig(ctx, poolConfig)
 		if err == nil {
 			if pingErr := pool.Ping(ctx); pingErr == nil {
 				cancel()
 				slog.Info("successfully connected to DB")
 				return pool, nil
 			} else {
+				pool.Close()
 				err = pingErr
 			}
 		}
 
 		cancel()
 
-		slog.Warn(fmt.Sprintf("DB not ready, attempt %d: %v\n", i+1, err))
-		time.Sleep(5 * time.Second)
+		slog.Warn("DB not ready", "attempt", i+1, "error", err)
+		time.Sleep(config.ConnectRetryDelay)
 	}
 
-	return pool, fmt.Errorf("failed to connect to DB after 10 attempts: %w", err)
+	return nil, fmt.Errorf("failed to connect to DB after %d attempts: %w", config.ConnectAttempts, err)
 }
 
-func (a *App) runMigrations(config config.Config) error {
+func (a *App) runMigrations(config config.DBConfig) error {
 	slog.Info("running database migrations")
 
-	db, err := sql.Open("pgx", config.DBConnectionString)
+	db, err := sql.Open("pgx", config.ConnectionString())
 	if err != nil {
 		return fmt.Errorf("failed to open sql.DB for migrations: %w", err)
 	}
 	defer db.Close()
 
-	if err := goose.Up(db, "./migrations"); err != nil {
+	if err := goose.Up(db, migrationsDir); err != nil {
 		return fmt.Errorf("failed to apply migrations: %w", err)
 	}
 
@@ -120,10 +183,38 @@ func (a *App) runMigrations(config config.Config) error {
 	return nil
 }
 
-func (a *App) initService(ctx context.Context, db *pgxpool.Pool, server *api.Server) {
-	teamService := team.NewService(teamrepo.NewRepo(db))
-	userService := user.NewService(userrepo.NewRepo(db))
-	prService := pr.NewService(prrepo.NewRepo(db))
+func (a *App) initService(repos repositories, server *api.Server, jwtConfig config.JWTConfig) error {
+	selectors := pr.NewSelectors()
+
+	teamService := team.NewService(repos.team, selectors)
+	userService := user.NewService(repos.user, selectors)
+	prService := pr.NewService(repos.pr, selectors)
+	statsService := stats.NewService(repos.stats)
+	healthService := health.NewService(repos.health, repos.latestMigration)
+
+	var verifier *auth.TokenVerifier
+	if jwtConfig.JWKS != "" {
+		var err error
+		verifier, err = auth.NewTokenVerifier(context.Background(), jwtConfig)
+		if err != nil {
+			return fmt.Errorf("failed to load jwks: %w", err)
+		}
+	}
+	authService := auth.NewService(repos.apiKey, verifier)
+
+	if repos.ephemeral {
+		key, _, err := authService.CreateKey(context.Background(), devAdminKeyName, domain.RoleAdmin, "")
+		if err != nil {
+			return fmt.Errorf("failed to create admin api key: %w", err)
+		}
 
-	server.HandleRoutes(ctx, teamService, userService, prService)
+		// The key is a secret: it is shown once on the terminal and kept out
+		// of the logs.
+		fmt.Fprintf(os.Stderr, "admin api key for in-memory storage: %s\n", key)
+		slog.Info("created admin api key for in-memory storage", "name", devAdminKeyName)
+	}
+
+	server.HandleRoutes(authService, teamService, userService, prService, statsService, healthService)
+
+	return nil
 }
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/dafuqqqyunglean/avito_tech/config"
+	apikeyrepo "github.com/dafuqqqyunglean/avito_tech/database/apikey"
+	healthrepo "github.com/dafuqqqyunglean/avito_tech/database/health"
+	"github.com/dafuqqqyunglean/avito_tech/database/memory"
+	prrepo "github.com/dafuqqqyunglean/avito_tech/database/pr"
+	"github.com/dafuqqqyunglean/avito_tech/database/sqlite"
+	statsrepo "github.com/dafuqqqyunglean/avito_tech/database/stats"
+	teamrepo "github.com/dafuqqqyunglean/avito_tech/database/team"
+	userrepo "github.com/dafuqqqyunglean/avito_tech/database/user"
+	"github.com/dafuqqqyunglean/avito_tech/metrics"
+	"github.com/dafuqqqyunglean/avito_tech/service/health"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// repositories is the storage backend the services run on.
+type repositories struct {
+	team   teamrepo.Repository
+	user   userrepo.Repository
+	pr     prrepo.Repository
+	stats  statsrepo.Repository
+	health healthrepo.Repository
+	apiKey apikeyrepo.Repository
+
+	// latestMigration is what readiness expects the schema at; nil for
+	// storage without a schema.
+	latestMigration health.LatestMigration
+	// ephemeral storage starts empty on every run, so nobody could have
+	// created an API key for it beforehand.
+	ephemeral bool
+}
+
+// initStorage opens the configured backend. The returned func releases it.
+func (a *App) initStorage(cfg config.Config) (repositories, func(), error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		slog.Warn("using in-memory storage, all data is lost on shutdown")
+
+		store := memory.NewStore()
+
+		return repositories{
+			team:      memory.NewTeamRepo(store),
+			user:      memory.NewUserRepo(store),
+			pr:        memory.NewPRRepo(store),
+			stats:     memory.NewStatsRepo(store),
+			health:    memory.NewHealthRepo(),
+			apiKey:    memory.NewAPIKeyRepo(store),
+			ephemeral: true,
+		}, func() {}, nil
+	case config.StoragePostgres:
+		pool, err := a.initDatabase(cfg.DB)
+		if err != nil {
+			return repositories{}, nil, fmt.Errorf("failed to connect to db: %w", err)
+		}
+
+		closePool := func() {
+			pool.Close()
+			slog.Info("database pool closed")
+		}
+
+		if err := a.runMigrations(cfg.DB); err != nil {
+			closePool()
+			return repositories{}, nil, fmt.Errorf("failed to apply migrations: %w", err)
+		}
+
+		prometheus.MustRegister(metrics.NewPoolCollector(pool))
+
+		return repositories{
+			team:            teamrepo.NewRepo(pool),
+			user:            userrepo.NewRepo(pool),
+			pr:              prrepo.NewRepo(pool),
+			stats:           statsrepo.NewRepo(pool),
+			health:          healthrepo.NewRepo(pool),
+			apiKey:          apikeyrepo.NewRepo(pool),
+			latestMigration: health.MigrationsDir(migrationsDir),
+		}, closePool, nil
+	case config.StorageSQLite:
+		slog.Info("opening SQLite database", "path", cfg.SQLite.Path)
+
+		db, err := sqlite.Open(cfg.SQLite.Path)
+		if err != nil {
+			return repositories{}, nil, fmt.Errorf("failed to open sqlite db: %w", err)
+		}
+
+		closeDB := func() {
+			if err := db.Close(); err != nil {
+				slog.Error("failed to close sqlite db", "error", err)
+				return
+			}
+			slog.Info("sqlite db closed")
+		}
+
+		if err := sqlite.Migrate(context.Background(), db); err != nil {
+			closeDB()
+			return repositories{}, nil, fmt.Errorf("failed to apply migrations: %w", err)
+		}
+
+		return repositories{
+			team:   sqlite.NewTeamRepo(db),
+			user:   sqlite.NewUserRepo(db),
+			pr:     sqlite.NewPRRepo(db),
+			stats:  sqlite.NewStatsRepo(db),
+			health: sqlite.NewHealthRepo(db),
+			apiKey: sqlite.NewAPIKeyRepo(db),
+			latestMigration: func() (int64, error) {
+				return sqlite.LatestMigration(db)
+			},
+		}, closeDB, nil
+	default:
+		return repositories{}, nil, fmt.Errorf("unknown storage %q", cfg.Storage)
+	}
+}
@@ -8,7 +8,13 @@ import (
 )
 
 func main() {
-	err := app.New().Run()
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		err = app.New().RunAPIKeys(os.Args[2:])
+	} else {
+		err = app.New().Run(os.Args[1:])
+	}
+
 	if err != nil {
 		slog.Warn("error occured", "error", err)
 		os.Exit(1)
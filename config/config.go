@@ -1,30 +1,375 @@
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.yaml.in/yaml/v3"
 )
 
+const configFileEnv = "CONFIG_FILE"
+
+// Storage backends selectable with -storage.
 const (
-	serverPortEnv = "SERVER_PORT"
-	dbHostEnv     = "DB_HOST"
-	dbPortEnv     = "DB_PORT"
-	dbUserEnv     = "DB_USER"
-	dbNameEnv     = "DB_NAME"
-	dbPasswordEnv = "DB_PASSWORD"
-	dbSSLModeEnv  = "DB_SSLMODE"
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
 )
 
+var storages = []string{StoragePostgres, StorageSQLite, StorageMemory}
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
 type Config struct {
-	ServerPort         string
-	DBConnectionString string
+	// Storage selects where data lives. The memory backend loses everything on
+	// restart and needs no database settings.
+	Storage string        `yaml:"storage"`
+	Server  ServerConfig  `yaml:"server"`
+	DB      DBConfig      `yaml:"db"`
+	SQLite  SQLiteConfig  `yaml:"sqlite"`
+	Tracing TracingConfig `yaml:"tracing"`
+	JWT     JWTConfig     `yaml:"jwt"`
+}
+
+type ServerConfig struct {
+	Port            int           `yaml:"port"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	MaxHeaderBytes  int           `yaml:"max_header_bytes"`
+	RequestTimeout  time.Duration `yaml:"request_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type DBConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+	// ConnectAttempts is how many times startup tries to reach the database,
+	// waiting ConnectRetryDelay between attempts.
+	ConnectAttempts   int           `yaml:"connect_attempts"`
+	ConnectTimeout    time.Duration `yaml:"connect_timeout"`
+	ConnectRetryDelay time.Duration `yaml:"connect_retry_delay"`
+}
+
+type SQLiteConfig struct {
+	// Path is the database file; it is created on first start.
+	Path string `yaml:"path"`
+}
+
+type TracingConfig struct {
+	// OTLPEndpoint is the host:port of an OTLP/HTTP trace collector. Tracing is
+	// disabled when it is empty.
+	OTLPEndpoint string `yaml:"otlp_endpoint"`
+	OTLPInsecure bool   `yaml:"otlp_insecure"`
+}
+
+type JWTConfig struct {
+	// JWKS is the file path or http(s) URL of the key set that signs bearer
+	// tokens. Bearer authentication is disabled when it is empty.
+	JWKS     string `yaml:"jwks"`
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// UserClaim holds the service's user ID, RoleClaim the caller's role(s) and
+	// TeamClaim the team of a team-maintainer.
+	UserClaim string `yaml:"user_claim"`
+	RoleClaim string `yaml:"role_claim"`
+	TeamClaim string `yaml:"team_claim"`
+}
+
+// Address is the listen address for the HTTP server.
+func (c ServerConfig) Address() string {
+	return fmt.Sprintf(":%d", c.Port)
 }
 
-func NewConfig() (Config, error) {
+func (c DBConfig) ConnectionString() string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     c.Name,
+		RawQuery: url.Values{"sslmode": {c.SSLMode}}.Encode(),
+	}
+
+	return dsn.String()
+}
+
+func defaults() Config {
 	return Config{
-		ServerPort: fmt.Sprintf(":%s", os.Getenv(serverPortEnv)),
-		DBConnectionString: fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-			os.Getenv(dbUserEnv), os.Getenv(dbPasswordEnv), os.Getenv(dbHostEnv),
-			os.Getenv(dbPortEnv), os.Getenv(dbNameEnv), os.Getenv(dbSSLModeEnv)),
-	}, nil
+		Storage: StoragePostgres,
+		Server: ServerConfig{
+			Port:            8080,
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			MaxHeaderBytes:  1 << 20,
+			RequestTimeout:  5 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+		},
+		DB: DBConfig{
+			Port:              5432,
+			SSLMode:           "disable",
+			ConnectAttempts:   10,
+			ConnectTimeout:    10 * time.Second,
+			ConnectRetryDelay: 5 * time.Second,
+		},
+		SQLite: SQLiteConfig{
+			Path: "pr-service.db",
+		},
+		JWT: JWTConfig{
+			UserClaim: "sub",
+			RoleClaim: "roles",
+			TeamClaim: "team",
+		},
+	}
+}
+
+// setting binds one config field to its environment variable and flag.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	set   func(string) error
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"STORAGE", "storage", "storage backend: postgres, sqlite or memory", stringSetter(&c.Storage)},
+		{"SERVER_PORT", "server-port", "HTTP listen port", intSetter(&c.Server.Port)},
+		{"SERVER_READ_TIMEOUT", "server-read-timeout", "HTTP read timeout", durationSetter(&c.Server.ReadTimeout)},
+		{"SERVER_WRITE_TIMEOUT", "server-write-timeout", "HTTP write timeout", durationSetter(&c.Server.WriteTimeout)},
+		{"SERVER_MAX_HEADER_BYTES", "server-max-header-bytes", "maximum size of request headers", intSetter(&c.Server.MaxHeaderBytes)},
+		{"REQUEST_TIMEOUT", "request-timeout", "deadline for handling a single request", durationSetter(&c.Server.RequestTimeout)},
+		{"SHUTDOWN_TIMEOUT", "shutdown-timeout", "time to drain in-flight requests on shutdown", durationSetter(&c.Server.ShutdownTimeout)},
+		{"DB_HOST", "db-host", "database host", stringSetter(&c.DB.Host)},
+		{"DB_PORT", "db-port", "database port", intSetter(&c.DB.Port)},
+		{"DB_USER", "db-user", "database user", stringSetter(&c.DB.User)},
+		{"DB_PASSWORD", "db-password", "database password", stringSetter(&c.DB.Password)},
+		{"DB_NAME", "db-name", "database name", stringSetter(&c.DB.Name)},
+		{"DB_SSLMODE", "db-sslmode", "database sslmode", stringSetter(&c.DB.SSLMode)},
+		{"DB_CONNECT_ATTEMPTS", "db-connect-attempts", "attempts to reach the database on startup", intSetter(&c.DB.ConnectAttempts)},
+		{"DB_CONNECT_TIMEOUT", "db-connect-timeout", "timeout of a single connection attempt", durationSetter(&c.DB.ConnectTimeout)},
+		{"DB_CONNECT_RETRY_DELAY", "db-connect-retry-delay", "pause between connection attempts", durationSetter(&c.DB.ConnectRetryDelay)},
+		{"SQLITE_PATH", "sqlite-path", "SQLite database file", stringSetter(&c.SQLite.Path)},
+		{"OTLP_ENDPOINT", "otlp-endpoint", "OTLP/HTTP trace collector host:port", stringSetter(&c.Tracing.OTLPEndpoint)},
+		{"OTLP_INSECURE", "otlp-insecure", "send traces without TLS", boolSetter(&c.Tracing.OTLPInsecure)},
+		{"JWT_JWKS", "jwt-jwks", "JWKS file or URL for bearer tokens", stringSetter(&c.JWT.JWKS)},
+		{"JWT_ISSUER", "jwt-issuer", "expected token issuer", stringSetter(&c.JWT.Issuer)},
+		{"JWT_AUDIENCE", "jwt-audience", "expected token audience", stringSetter(&c.JWT.Audience)},
+		{"JWT_USER_CLAIM", "jwt-user-claim", "claim holding the user ID", stringSetter(&c.JWT.UserClaim)},
+		{"JWT_ROLE_CLAIM", "jwt-role-claim", "claim holding the role", stringSetter(&c.JWT.RoleClaim)},
+		{"JWT_TEAM_CLAIM", "jwt-team-claim", "claim holding a team-maintainer's team", stringSetter(&c.JWT.TeamClaim)},
+	}
+}
+
+// Load builds the configuration from, in increasing priority: defaults, an
+// optional YAML file (-config or CONFIG_FILE), environment variables and
+// command line flags. Every invalid value and missing field is reported in a
+// single joined error.
+func Load(args []string) (Config, error) {
+	config, _, err := LoadCommand(args)
+	return config, err
+}
+
+// LoadCommand is Load for subcommands taking flags of their own, as in
+// "server apikey -config prod.yaml create -name ci". Flags are read up to the
+// first non-flag argument, which is returned with everything after it.
+func LoadCommand(args []string) (Config, []string, error) {
+	config := defaults()
+	settings := config.settings()
+
+	flags := flag.NewFlagSet("server", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv(configFileEnv), "path to a YAML config file")
+
+	flagValues := make(map[string]string)
+	for _, s := range settings {
+		flags.Func(s.flag, s.usage, func(value string) error {
+			flagValues[s.flag] = value
+			return nil
+		})
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	var errs []error
+
+	if *configFile != "" {
+		if err := config.loadFile(*configFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid %s: %w", s.env, err))
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := flagValues[s.flag]; ok {
+			if err := s.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid -%s: %w", s.flag, err))
+			}
+		}
+	}
+
+	errs = append(errs, config.validate()...)
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, nil, err
+	}
+
+	return config, flags.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c Config) validate() []error {
+	var errs []error
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("server port must be between 1 and 65535, got %d", c.Server.Port))
+	}
+	for _, timeout := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"server read timeout", c.Server.ReadTimeout},
+		{"server write timeout", c.Server.WriteTimeout},
+		{"request timeout", c.Server.RequestTimeout},
+		{"shutdown timeout", c.Server.ShutdownTimeout},
+	} {
+		if timeout.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", timeout.name))
+		}
+	}
+	if c.Server.MaxHeaderBytes <= 0 {
+		errs = append(errs, errors.New("server max header bytes must be positive"))
+	}
+
+	if !slices.Contains(storages, c.Storage) {
+		errs = append(errs, fmt.Errorf("storage must be one of %v, got %q", storages, c.Storage))
+	}
+	switch c.Storage {
+	case StoragePostgres:
+		errs = append(errs, c.DB.validate()...)
+	case StorageSQLite:
+		if c.SQLite.Path == "" {
+			errs = append(errs, errors.New("sqlite path is required"))
+		}
+	}
+
+	if c.JWT.JWKS != "" {
+		if c.JWT.Issuer == "" {
+			errs = append(errs, errors.New("jwt issuer is required when jwks is set"))
+		}
+		if c.JWT.Audience == "" {
+			errs = append(errs, errors.New("jwt audience is required when jwks is set"))
+		}
+		if c.JWT.UserClaim == "" || c.JWT.RoleClaim == "" || c.JWT.TeamClaim == "" {
+			errs = append(errs, errors.New("jwt user, role and team claims must not be empty"))
+		}
+	}
+
+	return errs
+}
+
+func (c DBConfig) validate() []error {
+	var errs []error
+
+	if c.Host == "" {
+		errs = append(errs, errors.New("db host is required"))
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("db port must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.User == "" {
+		errs = append(errs, errors.New("db user is required"))
+	}
+	if c.Name == "" {
+		errs = append(errs, errors.New("db name is required"))
+	}
+	if !slices.Contains(sslModes, c.SSLMode) {
+		errs = append(errs, fmt.Errorf("db sslmode must be one of %v, got %q", sslModes, c.SSLMode))
+	}
+	if c.ConnectAttempts < 1 {
+		errs = append(errs, errors.New("db connect attempts must be at least 1"))
+	}
+	if c.ConnectTimeout <= 0 {
+		errs = append(errs, errors.New("db connect timeout must be positive"))
+	}
+	if c.ConnectRetryDelay < 0 {
+		errs = append(errs, errors.New("db connect retry delay must not be negative"))
+	}
+
+	return errs
+}
+
+func stringSetter(field *string) func(string) error {
+	return func(value string) error {
+		*field = value
+		return nil
+	}
+}
+
+func intSetter(field *int) func(string) error {
+	return func(value string) error {
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		*field = number
+		return nil
+	}
+}
+
+func boolSetter(field *bool) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		*field = parsed
+		return nil
+	}
+}
+
+func durationSetter(field *time.Duration) func(string) error {
+	return func(value string) error {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		*field = duration
+		return nil
+	}
 }
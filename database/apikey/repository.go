@@ -0,0 +1,123 @@
+package apikey
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	Create(ctx context.Context, name, keyHash, role, teamName string) (domain.APIKey, error)
+	GetActive(ctx context.Context, keyHash string) (domain.APIKey, error)
+	List(ctx context.Context) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+}
+
+type repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepo(db *pgxpool.Pool) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+//go:embed sql/*.sql
+var queries embed.FS
+
+func init() {
+	tracing.MustRegisterQueries(queries)
+}
+
+//go:embed sql/getTeamID.sql
+var getTeamID string
+
+//go:embed sql/createAPIKey.sql
+var createAPIKey string
+
+func (r *repository) Create(ctx context.Context, name, keyHash, role, teamName string) (domain.APIKey, error) {
+	var teamID *int
+	if teamName != "" {
+		teamID = new(int)
+		err := r.db.QueryRow(ctx, getTeamID, teamName).Scan(teamID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.APIKey{}, domain.ErrNotFound
+		}
+		if err != nil {
+			return domain.APIKey{}, fmt.Errorf("failed to get team: %w", err)
+		}
+	}
+
+	key := domain.APIKey{
+		Name:     name,
+		Role:     role,
+		TeamName: teamName,
+	}
+
+	err := r.db.QueryRow(ctx, createAPIKey, name, keyHash, role, teamID).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return key, nil
+}
+
+//go:embed sql/getActiveAPIKey.sql
+var getActiveAPIKey string
+
+func (r *repository) GetActive(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	rows, err := r.db.Query(ctx, getActiveAPIKey, keyHash)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	key, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByPos[domain.APIKey])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to scan api key: %w", err)
+	}
+
+	return key, nil
+}
+
+//go:embed sql/listAPIKeys.sql
+var listAPIKeys string
+
+func (r *repository) List(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := r.db.Query(ctx, listAPIKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByPos[domain.APIKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+//go:embed sql/revokeAPIKey.sql
+var revokeAPIKey string
+
+func (r *repository) Revoke(ctx context.Context, id int64) error {
+	tag, err := r.db.Exec(ctx, revokeAPIKey, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,3 @@
+INSERT INTO api_keys (name, key_hash, role, team_id)
+VALUES ($1, $2, $3, $4)
+RETURNING id, created_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT id
+FROM teams
+WHERE name = $1;
\ No newline at end of file
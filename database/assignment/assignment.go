@@ -0,0 +1,214 @@
+// Package assignment holds the reviewer assignment steps the Postgres
+// repositories share, so a review is released the same way whether its
+// reviewer is deactivated, leaves the team or loses it.
+package assignment
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+//go:embed sql/*.sql
+var queries embed.FS
+
+func init() {
+	tracing.MustRegisterQueries(queries)
+}
+
+// Querier is what the read-only steps need; both *pgxpool.Pool and pgx.Tx
+// implement it.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+//go:embed sql/lockPR.sql
+var lockPR string
+
+// LockPR locks the PR row until commit and returns its status.
+func LockPR(ctx context.Context, tx pgx.Tx, prID string) (string, error) {
+	var status string
+	err := tx.QueryRow(ctx, lockPR, prID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", domain.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock pr %s: %w", prID, err)
+	}
+
+	return status, nil
+}
+
+//go:embed sql/getTeamSettings.sql
+var getTeamSettings string
+
+func TeamSettings(ctx context.Context, q Querier, teamName string) (domain.TeamSettings, error) {
+	var settings domain.TeamSettings
+	err := q.QueryRow(ctx, getTeamSettings, teamName).Scan(&settings.TeamName,
+		&settings.AssignmentStrategy,
+		&settings.MinReviewers,
+		&settings.MaxReviewers)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+//go:embed sql/getPRTeam.sql
+var getPRTeam string
+
+// Pool returns the PR's team as a pool without candidates, and the PR's
+// author. A PR without a team gives domain.ErrNotFound.
+func Pool(ctx context.Context, q Querier, prID string) (domain.ReviewerPool, string, error) {
+	var (
+		pool     domain.ReviewerPool
+		authorID string
+	)
+	err := q.QueryRow(ctx, getPRTeam, prID).Scan(&pool.TeamName,
+		&pool.Strategy,
+		&pool.MinReviewers,
+		&pool.MaxReviewers,
+		&authorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ReviewerPool{}, "", domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.ReviewerPool{}, "", fmt.Errorf("failed to get team of %s: %w", prID, err)
+	}
+
+	return pool, authorID, nil
+}
+
+//go:embed sql/selectReplacementCandidates.sql
+var selectReplacementCandidates string
+
+// Candidates returns the active members of teamName who could take userID's
+// review of the PR: neither its author nor one of its reviewers.
+func Candidates(ctx context.Context, q Querier, prID, userID, teamName string) ([]domain.ReviewCandidate, error) {
+	rows, err := q.Query(ctx, selectReplacementCandidates, prID, userID, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replacement candidates for %s: %w", prID, err)
+	}
+
+	candidates, err := pgx.CollectRows(rows, pgx.RowToStructByPos[domain.ReviewCandidate])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan replacement candidates for %s: %w", prID, err)
+	}
+
+	return candidates, nil
+}
+
+//go:embed sql/insertAssignmentEvent.sql
+var insertAssignmentEvent string
+
+// RecordEvent appends an event to the PR's assignment history on behalf of
+// the actor in ctx. Empty reviewer IDs are stored as NULL.
+func RecordEvent(ctx context.Context, tx pgx.Tx, prID, event, oldReviewerID, newReviewerID, reason string) error {
+	_, err := tx.Exec(ctx, insertAssignmentEvent, prID, event, nullable(oldReviewerID), nullable(newReviewerID),
+		domain.ActorFromContext(ctx), reason)
+	if err != nil {
+		return fmt.Errorf("failed to record %s event of %s: %w", event, prID, err)
+	}
+
+	return nil
+}
+
+//go:embed sql/replaceReviewer.sql
+var replaceReviewer string
+
+//go:embed sql/removeReviewer.sql
+var removeReviewer string
+
+// Release hands userID's review of the PR over to a member of the PR's team
+// picked by that team's strategy, or drops userID from the PR when nobody is
+// left. A PR whose team is gone keeps an empty pool and loses the reviewer.
+//
+// The PR row stays locked until commit, so the replacement is picked from a
+// reviewer list no concurrent reassign or merge can change. It reports false
+// when, by the time the lock is taken, the PR is no longer OPEN or userID no
+// longer reviews it.
+func Release(ctx context.Context, tx pgx.Tx, prID, userID, reason string, selectReviewers domain.SelectReviewers) (domain.Reassignment, bool, error) {
+	status, err := LockPR(ctx, tx, prID)
+	if err != nil {
+		return domain.Reassignment{}, false, err
+	}
+	if status != domain.StatusOpen {
+		return domain.Reassignment{}, false, nil
+	}
+
+	pool, _, err := Pool(ctx, tx, prID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.Reassignment{}, false, err
+	}
+
+	pool.Candidates, err = Candidates(ctx, tx, prID, userID, pool.TeamName)
+	if err != nil {
+		return domain.Reassignment{}, false, err
+	}
+
+	reassignment := domain.Reassignment{
+		PrID:          prID,
+		OldReviewerID: userID,
+	}
+
+	event, query, args := domain.EventUnassigned, removeReviewer, []any{prID, userID}
+	if picked := selectReviewers(pool, 1); len(picked) > 0 {
+		reassignment.NewReviewerID = picked[0]
+		event, query, args = domain.EventReassigned, replaceReviewer, []any{prID, userID, picked[0]}
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return domain.Reassignment{}, false, ConstraintError(err, "failed to release review of "+prID)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.Reassignment{}, false, nil
+	}
+
+	if err := RecordEvent(ctx, tx, prID, event, userID, reassignment.NewReviewerID, reason); err != nil {
+		return domain.Reassignment{}, false, err
+	}
+
+	return reassignment, true, nil
+}
+
+// Postgres error codes of the integrity violations ConstraintError maps.
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+// ConstraintError maps integrity violations, which concurrent writers can still
+// provoke, to domain errors instead of surfacing them as internal errors.
+func ConstraintError(err error, message string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return domain.ErrNoCandidate
+		case foreignKeyViolation:
+			return domain.ErrNotFound
+		}
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+func nullable(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
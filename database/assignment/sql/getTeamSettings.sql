@@ -0,0 +1,3 @@
+SELECT name, assignment_strategy, min_reviewers, max_reviewers
+FROM teams
+WHERE name = $1;
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO review_assignments (pr_id, event, old_reviewer_id, new_reviewer_id, actor, reason)
+VALUES ($1, $2, $3, $4, $5, $6);
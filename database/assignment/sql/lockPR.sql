@@ -0,0 +1,4 @@
+SELECT status
+FROM pull_requests
+WHERE id = $1
+FOR UPDATE;
\ No newline at end of file
@@ -0,0 +1,4 @@
+UPDATE pr_reviewers
+SET user_id = $3
+WHERE pr_id = $1
+  AND user_id = $2;
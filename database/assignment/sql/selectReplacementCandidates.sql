@@ -0,0 +1,21 @@
+SELECT u.id, COUNT(pr.id) AS open_reviews
+FROM team_members tm
+JOIN teams t ON tm.team_id = t.id
+JOIN users u ON tm.user_id = u.id
+LEFT JOIN pr_reviewers rv ON rv.user_id = u.id
+LEFT JOIN pull_requests pr ON pr.id = rv.pr_id AND pr.status = 'OPEN'
+WHERE t.name = $3
+  AND u.id != $2
+  AND u.is_active = true
+  AND u.id != (
+      SELECT author_id
+      FROM pull_requests
+      WHERE id = $1
+  )
+  AND u.id NOT IN (
+      SELECT user_id
+      FROM pr_reviewers
+      WHERE pr_id = $1
+  )
+GROUP BY u.id
+ORDER BY u.id;
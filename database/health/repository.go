@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+type Repository interface {
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (int64, error)
+}
+
+type repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepo(db *pgxpool.Pool) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+func (r *repository) Ping(ctx context.Context) error {
+	if err := r.db.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping db: %w", err)
+	}
+
+	return nil
+}
+
+func (r *repository) MigrationVersion(ctx context.Context) (int64, error) {
+	db := stdlib.OpenDBFromPool(r.db)
+	defer db.Close()
+
+	version, err := goose.GetDBVersionContext(ctx, db)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	apikeyrepo "github.com/dafuqqqyunglean/avito_tech/database/apikey"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+type apiKey struct {
+	domain.APIKey
+	hash string
+}
+
+type apiKeyRepository struct {
+	store *Store
+}
+
+func NewAPIKeyRepo(store *Store) apikeyrepo.Repository {
+	return &apiKeyRepository{
+		store: store,
+	}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, name, keyHash, role, teamName string) (domain.APIKey, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if teamName != "" {
+		if _, ok := s.teams[teamName]; !ok {
+			return domain.APIKey{}, domain.ErrNotFound
+		}
+	}
+
+	s.nextKeyID++
+	key := &apiKey{
+		APIKey: domain.APIKey{
+			ID:        s.nextKeyID,
+			Name:      name,
+			Role:      role,
+			TeamName:  teamName,
+			CreatedAt: now(),
+		},
+		hash: keyHash,
+	}
+	s.apiKeys = append(s.apiKeys, key)
+
+	return key.APIKey, nil
+}
+
+func (r *apiKeyRepository) GetActive(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.apiKeys, func(key *apiKey) bool {
+		return key.hash == keyHash && key.RevokedAt == nil
+	})
+	if i < 0 {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+
+	return s.apiKeys[i].APIKey, nil
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := []domain.APIKey{}
+	for _, key := range s.apiKeys {
+		keys = append(keys, key.APIKey)
+	}
+
+	return keys, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id int64) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.apiKeys, func(key *apiKey) bool {
+		return key.ID == id && key.RevokedAt == nil
+	})
+	if i < 0 {
+		return domain.ErrNotFound
+	}
+
+	revokedAt := time.Now().UTC()
+	s.apiKeys[i].RevokedAt = &revokedAt
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"context"
+
+	healthrepo "github.com/dafuqqqyunglean/avito_tech/database/health"
+)
+
+type healthRepository struct{}
+
+// NewHealthRepo reports the in-memory store as always reachable. It has no
+// schema, so readiness must be checked without migrations.
+func NewHealthRepo() healthrepo.Repository {
+	return healthRepository{}
+}
+
+func (healthRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (healthRepository) MigrationVersion(ctx context.Context) (int64, error) {
+	return 0, nil
+}
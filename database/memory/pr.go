@@ -0,0 +1,306 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+
+	prrepo "github.com/dafuqqqyunglean/avito_tech/database/pr"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+type prRepository struct {
+	store *Store
+}
+
+func NewPRRepo(store *Store) prrepo.Repository {
+	return &prRepository{
+		store: store,
+	}
+}
+
+func (r *prRepository) Create(ctx context.Context, prID, prName, authorID, teamName, status string, selectReviewers domain.SelectReviewers) (domain.CreatePRResponse, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.authorTeam(authorID, teamName)
+	if err != nil {
+		return domain.CreatePRResponse{}, err
+	}
+
+	if _, ok := s.prs[prID]; ok {
+		return domain.CreatePRResponse{}, domain.ErrPRExists
+	}
+
+	pr := &pullRequest{
+		PullRequest: domain.PullRequest{
+			ID:        prID,
+			Name:      prName,
+			AuthorID:  authorID,
+			Status:    status,
+			Reviewers: []string{},
+			CreatedAt: now(),
+		},
+		team: t,
+	}
+
+	if status == domain.StatusOpen {
+		pool := s.pool(t, authorID)
+
+		pr.Reviewers = selectReviewers(pool, pool.MaxReviewers)
+		if len(pr.Reviewers) < pool.MinReviewers {
+			return domain.CreatePRResponse{}, domain.ErrNotEnoughReviewers
+		}
+	}
+
+	s.prs[prID] = pr
+
+	for _, reviewerID := range pr.Reviewers {
+		s.record(prID, domain.EventAssigned, "", reviewerID, domain.ActorFromContext(ctx), domain.ReasonPRCreated)
+	}
+
+	return domain.CreatePRResponse{PR: pr.snapshot()}, nil
+}
+
+func (r *prRepository) Get(ctx context.Context, prID string) (domain.PullRequest, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr, ok := s.prs[prID]
+	if !ok {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	return pr.snapshot(), nil
+}
+
+func (r *prRepository) GetStatus(ctx context.Context, prID string) (string, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr, ok := s.prs[prID]
+	if !ok {
+		return "", domain.ErrNotFound
+	}
+
+	return pr.Status, nil
+}
+
+func (r *prRepository) List(ctx context.Context, filter domain.PRFilter) (domain.ListPRResponse, error) {
+	var cursor *domain.PullRequest
+	if filter.Cursor != "" {
+		createdAt, id, err := prrepo.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return domain.ListPRResponse{}, domain.ErrBadRequest
+		}
+
+		cursor = &domain.PullRequest{ID: id, CreatedAt: createdAt}
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matched := []domain.PullRequest{}
+	for _, stored := range s.prs {
+		pr := stored.snapshot()
+		if !s.matches(pr, filter) || (cursor != nil && comparePRs(pr, *cursor) >= 0) {
+			continue
+		}
+
+		slices.Sort(pr.Reviewers)
+		matched = append(matched, pr)
+	}
+
+	// Newest first, the same order the cursor walks in.
+	slices.SortFunc(matched, func(a, b domain.PullRequest) int {
+		return comparePRs(b, a)
+	})
+
+	resp := domain.ListPRResponse{
+		PullRequests: matched,
+	}
+
+	if len(matched) > filter.Limit {
+		resp.PullRequests = matched[:filter.Limit]
+
+		last := resp.PullRequests[len(resp.PullRequests)-1]
+		resp.NextCursor = prrepo.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return resp, nil
+}
+
+func (s *Store) matches(pr domain.PullRequest, filter domain.PRFilter) bool {
+	switch {
+	case filter.AuthorID != "" && pr.AuthorID != filter.AuthorID,
+		filter.ReviewerID != "" && !slices.Contains(pr.Reviewers, filter.ReviewerID),
+		filter.TeamName != "" && pr.TeamName != filter.TeamName,
+		filter.Status != "" && pr.Status != filter.Status,
+		filter.CreatedFrom != nil && pr.CreatedAt.Before(*filter.CreatedFrom),
+		filter.CreatedTo != nil && !pr.CreatedAt.Before(*filter.CreatedTo):
+		return false
+	}
+
+	if filter.MergedFrom != nil || filter.MergedTo != nil {
+		if pr.MergedAt == nil ||
+			(filter.MergedFrom != nil && pr.MergedAt.Before(*filter.MergedFrom)) ||
+			(filter.MergedTo != nil && !pr.MergedAt.Before(*filter.MergedTo)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// comparePRs orders PRs by their (created_at, id) list key.
+func comparePRs(a, b domain.PullRequest) int {
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+
+	return strings.Compare(a.ID, b.ID)
+}
+
+func (r *prRepository) SetStatus(ctx context.Context, prID, from, to string, selectReviewers domain.SelectReviewers) (domain.PullRequest, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr, ok := s.prs[prID]
+	if !ok {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+	if pr.Status != from {
+		return domain.PullRequest{}, domain.ErrInvalidTransition
+	}
+
+	reviewers := []string{}
+	if to == domain.StatusOpen && len(pr.Reviewers) == 0 {
+		if pr.team == nil {
+			return domain.PullRequest{}, domain.ErrPRHasNoTeam
+		}
+
+		pool := s.pool(pr.team, pr.AuthorID)
+
+		reviewers = selectReviewers(pool, pool.MaxReviewers)
+		if len(reviewers) < pool.MinReviewers {
+			return domain.PullRequest{}, domain.ErrNotEnoughReviewers
+		}
+	}
+
+	pr.Status = to
+	pr.closedAt = nil
+	if to == domain.StatusClosed {
+		closedAt := now()
+		pr.closedAt = &closedAt
+	}
+
+	reason := domain.ReasonPRReopened
+	if from == domain.StatusDraft {
+		reason = domain.ReasonPRReady
+	}
+
+	for _, reviewerID := range reviewers {
+		pr.Reviewers = append(pr.Reviewers, reviewerID)
+		s.record(prID, domain.EventAssigned, "", reviewerID, domain.ActorFromContext(ctx), reason)
+	}
+
+	return pr.snapshot(), nil
+}
+
+func (r *prRepository) SetMerged(ctx context.Context, prID string) (domain.MergePRResponse, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr, ok := s.prs[prID]
+	if !ok {
+		return domain.MergePRResponse{}, domain.ErrNotFound
+	}
+
+	switch pr.Status {
+	case domain.StatusMerged:
+	case domain.StatusOpen:
+		mergedAt := now()
+		pr.Status = domain.StatusMerged
+		pr.MergedAt = &mergedAt
+
+		s.record(prID, domain.EventMerged, "", "", domain.ActorFromContext(ctx), domain.ReasonPRMerged)
+	default:
+		return domain.MergePRResponse{}, domain.ErrInvalidTransition
+	}
+
+	return domain.MergePRResponse{
+		PR:       pr.snapshot(),
+		MergedAt: *pr.MergedAt,
+	}, nil
+}
+
+func (r *prRepository) GetHistory(ctx context.Context, prID string) (domain.PRHistoryResponse, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.prs[prID]; !ok {
+		return domain.PRHistoryResponse{}, domain.ErrNotFound
+	}
+
+	events := []domain.AssignmentEvent{}
+	for _, e := range s.events {
+		if e.prID == prID {
+			events = append(events, e.AssignmentEvent)
+		}
+	}
+
+	// Events of one transaction share a timestamp in Postgres; IDs break ties
+	// there and keep insertion order here.
+	slices.SortStableFunc(events, func(a, b domain.AssignmentEvent) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return domain.PRHistoryResponse{
+		PrID:   prID,
+		Events: events,
+	}, nil
+}
+
+func (r *prRepository) Reassign(ctx context.Context, prID, oldUserID string, selectReviewers domain.SelectReviewers) (domain.ReassignPRResponse, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pr, ok := s.prs[prID]
+	if !ok {
+		return domain.ReassignPRResponse{}, domain.ErrNotFound
+	}
+	if pr.Status == domain.StatusMerged {
+		return domain.ReassignPRResponse{}, domain.ErrPRMerged
+	}
+	if pr.Status != domain.StatusOpen {
+		return domain.ReassignPRResponse{}, domain.ErrInvalidTransition
+	}
+
+	i := slices.Index(pr.Reviewers, oldUserID)
+	if i < 0 || pr.team == nil {
+		return domain.ReassignPRResponse{}, domain.ErrNotFound
+	}
+
+	replacement := selectReviewers(s.replacementPool(pr.team, pr, oldUserID), 1)
+	if len(replacement) == 0 {
+		return domain.ReassignPRResponse{}, domain.ErrNoCandidate
+	}
+
+	pr.Reviewers[i] = replacement[0]
+
+	s.record(prID, domain.EventReassigned, oldUserID, replacement[0], domain.ActorFromContext(ctx), domain.ReasonManualReassign)
+
+	return domain.ReassignPRResponse{
+		PR:         pr.snapshot(),
+		ReplacedBy: replacement[0],
+	}, nil
+}
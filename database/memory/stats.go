@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	statsrepo "github.com/dafuqqqyunglean/avito_tech/database/stats"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+type statsRepository struct {
+	store *Store
+}
+
+func NewStatsRepo(store *Store) statsrepo.Repository {
+	return &statsRepository{
+		store: store,
+	}
+}
+
+// GetAssignmentStats counts team figures by the team each PR belongs to, like
+// the SQL backends; PRs of a deleted team are counted for users only.
+func (r *statsRepository) GetAssignmentStats(ctx context.Context, from, to *time.Time) (domain.AssignmentStatsResponse, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make(map[string]*domain.UserAssignmentStats)
+	userStats := func(userID string) *domain.AssignmentStats {
+		user, ok := users[userID]
+		if !ok {
+			user = &domain.UserAssignmentStats{UserID: userID, Username: s.users[userID].Name}
+			users[userID] = user
+		}
+
+		return &user.AssignmentStats
+	}
+
+	teams := make(map[string]*domain.TeamAssignmentStats)
+	teamStats := func(pr *pullRequest) *domain.AssignmentStats {
+		if pr.team == nil {
+			return &domain.AssignmentStats{}
+		}
+
+		name := pr.team.settings.TeamName
+		team, ok := teams[name]
+		if !ok {
+			team = &domain.TeamAssignmentStats{TeamName: name}
+			teams[name] = team
+		}
+
+		return &team.AssignmentStats
+	}
+
+	// Open and merged count each PR once per reviewer who still holds it.
+	type review struct{ userID, prID string }
+	counted := make(map[review]bool)
+
+	for _, e := range s.events {
+		if (from != nil && e.CreatedAt.Before(*from)) || (to != nil && !e.CreatedAt.Before(*to)) {
+			continue
+		}
+
+		pr := s.prs[e.prID]
+
+		if e.OldReviewerID != nil {
+			userStats(*e.OldReviewerID).ReassignedAway++
+			teamStats(pr).ReassignedAway++
+		}
+
+		if e.NewReviewerID == nil {
+			continue
+		}
+
+		userID := *e.NewReviewerID
+		user, team := userStats(userID), teamStats(pr)
+		user.Assigned++
+		team.Assigned++
+
+		key := review{userID, e.prID}
+		if counted[key] || !slices.Contains(pr.Reviewers, userID) {
+			continue
+		}
+		counted[key] = true
+
+		switch pr.Status {
+		case domain.StatusOpen:
+			user.Open++
+			team.Open++
+		case domain.StatusMerged:
+			user.Merged++
+			team.Merged++
+		}
+	}
+
+	resp := domain.AssignmentStatsResponse{
+		From:  from,
+		To:    to,
+		Users: []domain.UserAssignmentStats{},
+		Teams: []domain.TeamAssignmentStats{},
+	}
+
+	for _, userID := range slices.Sorted(maps.Keys(users)) {
+		resp.Users = append(resp.Users, *users[userID])
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(teams)) {
+		resp.Teams = append(resp.Teams, *teams[name])
+	}
+
+	return resp, nil
+}
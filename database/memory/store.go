@@ -0,0 +1,177 @@
+// Package memory keeps the service's data in process memory. It implements the
+// same repository interfaces as the Postgres packages, with the same errors and
+// ordering, for tests and for running the API without a database.
+package memory
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+// Store holds all data shared by the in-memory repositories. A single mutex
+// guards it, so every repository call behaves like one serializable
+// transaction.
+type Store struct {
+	mu sync.Mutex
+
+	users   map[string]*domain.User
+	teams   map[string]*team
+	prs     map[string]*pullRequest
+	events  []event
+	apiKeys []*apiKey
+
+	nextTeamID  int
+	nextEventID int64
+	nextKeyID   int64
+}
+
+type team struct {
+	id       int
+	settings domain.TeamSettings
+	members  []string
+}
+
+type pullRequest struct {
+	domain.PullRequest
+	// team reviews the PR; nil once the team is gone.
+	team     *team
+	closedAt *time.Time
+}
+
+type event struct {
+	prID string
+	domain.AssignmentEvent
+}
+
+func NewStore() *Store {
+	return &Store{
+		users: make(map[string]*domain.User),
+		teams: make(map[string]*team),
+		prs:   make(map[string]*pullRequest),
+	}
+}
+
+// now matches the precision Postgres keeps for TIMESTAMP columns, so cursors
+// and comparisons behave the same on both backends.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// userTeams returns the names of the teams the user belongs to, sorted.
+func (s *Store) userTeams(userID string) []string {
+	names := []string{}
+	for name, t := range s.teams {
+		if slices.Contains(t.members, userID) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// authorTeam picks the team a new PR of the author goes to: teamName, or the
+// author's only team when teamName is empty.
+func (s *Store) authorTeam(authorID, teamName string) (*team, error) {
+	var found []*team
+	for name, t := range s.teams {
+		if (teamName == "" || name == teamName) && slices.Contains(t.members, authorID) {
+			found = append(found, t)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, domain.ErrNotFound
+	case 1:
+		return found[0], nil
+	default:
+		return nil, domain.ErrAmbiguousTeam
+	}
+}
+
+// pool describes the team's reviewer settings together with its active members
+// that are not excluded, ordered by ID with their open review counts.
+func (s *Store) pool(t *team, exclude ...string) domain.ReviewerPool {
+	pool := domain.ReviewerPool{
+		TeamName:     t.settings.TeamName,
+		Strategy:     t.settings.AssignmentStrategy,
+		MinReviewers: t.settings.MinReviewers,
+		MaxReviewers: t.settings.MaxReviewers,
+		Candidates:   []domain.ReviewCandidate{},
+	}
+
+	members := slices.Sorted(slices.Values(t.members))
+	for _, userID := range members {
+		if !s.users[userID].IsActive || slices.Contains(exclude, userID) {
+			continue
+		}
+
+		pool.Candidates = append(pool.Candidates, domain.ReviewCandidate{
+			UserID:      userID,
+			OpenReviews: s.openReviews(userID),
+		})
+	}
+
+	return pool
+}
+
+// replacementPool is the pool for taking over userID's review of pr: the
+// author and the PR's current reviewers are not eligible.
+func (s *Store) replacementPool(t *team, pr *pullRequest, userID string) domain.ReviewerPool {
+	exclude := append([]string{userID, pr.AuthorID}, pr.Reviewers...)
+	return s.pool(t, exclude...)
+}
+
+func (s *Store) openReviews(userID string) int {
+	count := 0
+	for _, pr := range s.prs {
+		if pr.Status == domain.StatusOpen && slices.Contains(pr.Reviewers, userID) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (s *Store) record(prID, kind, oldReviewerID, newReviewerID, actor, reason string) {
+	s.nextEventID++
+	s.events = append(s.events, event{
+		prID: prID,
+		AssignmentEvent: domain.AssignmentEvent{
+			ID:            s.nextEventID,
+			Event:         kind,
+			OldReviewerID: nullable(oldReviewerID),
+			NewReviewerID: nullable(newReviewerID),
+			Actor:         actor,
+			Reason:        reason,
+			CreatedAt:     now(),
+		},
+	})
+}
+
+// snapshot copies the PR so callers never share the stored reviewer slice.
+func (pr *pullRequest) snapshot() domain.PullRequest {
+	out := pr.PullRequest
+	out.Reviewers = slices.Clone(pr.Reviewers)
+	if pr.team != nil {
+		out.TeamName = pr.team.settings.TeamName
+	}
+	if pr.MergedAt != nil {
+		mergedAt := *pr.MergedAt
+		out.MergedAt = &mergedAt
+	}
+
+	return out
+}
+
+func nullable(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
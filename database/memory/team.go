@@ -0,0 +1,333 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	teamrepo "github.com/dafuqqqyunglean/avito_tech/database/team"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+// Defaults the teams table applies to columns CreateTeam leaves out.
+const (
+	defaultMinReviewers = 1
+	defaultMaxReviewers = 2
+)
+
+type teamRepository struct {
+	store *Store
+}
+
+func NewTeamRepo(store *Store) teamrepo.Repository {
+	return &teamRepository{
+		store: store,
+	}
+}
+
+func (r *teamRepository) CreateTeam(ctx context.Context, teamName, strategy string, members []domain.User) (domain.TeamRequest, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[teamName]; ok {
+		return domain.TeamRequest{}, domain.ErrTeamExists
+	}
+
+	s.nextTeamID++
+	t := &team{
+		id: s.nextTeamID,
+		settings: domain.TeamSettings{
+			TeamName:           teamName,
+			AssignmentStrategy: strategy,
+			MinReviewers:       defaultMinReviewers,
+			MaxReviewers:       defaultMaxReviewers,
+		},
+	}
+
+	for _, member := range members {
+		user := member
+		s.users[member.ID] = &user
+
+		if !slices.Contains(t.members, member.ID) {
+			t.members = append(t.members, member.ID)
+		}
+	}
+
+	s.teams[teamName] = t
+
+	return domain.TeamRequest{TeamName: teamName, AssignmentStrategy: strategy, Members: members}, nil
+}
+
+func (r *teamRepository) GetTeam(ctx context.Context, teamName string) (domain.TeamRequest, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamName]
+	if !ok || len(t.members) == 0 {
+		return domain.TeamRequest{}, domain.ErrNotFound
+	}
+
+	return s.teamRequest(t), nil
+}
+
+func (s *Store) teamRequest(t *team) domain.TeamRequest {
+	team := domain.TeamRequest{
+		TeamName:           t.settings.TeamName,
+		AssignmentStrategy: t.settings.AssignmentStrategy,
+		Members:            []domain.User{},
+	}
+
+	for _, userID := range slices.Sorted(slices.Values(t.members)) {
+		team.Members = append(team.Members, *s.users[userID])
+	}
+
+	return team
+}
+
+func (r *teamRepository) GetSettings(ctx context.Context, teamName string) (domain.TeamSettings, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamName]
+	if !ok {
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+
+	return t.settings, nil
+}
+
+func (r *teamRepository) UpdateSettings(ctx context.Context, settings domain.TeamSettings) (domain.TeamSettings, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[settings.TeamName]
+	if !ok {
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+
+	t.settings = settings
+
+	return t.settings, nil
+}
+
+func (r *teamRepository) AddMembers(ctx context.Context, teamName string, members []domain.User) (domain.TeamRequest, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamName]
+	if !ok {
+		return domain.TeamRequest{}, domain.ErrNotFound
+	}
+
+	for _, member := range members {
+		user := member
+		s.users[member.ID] = &user
+
+		if !slices.Contains(t.members, member.ID) {
+			t.members = append(t.members, member.ID)
+		}
+	}
+
+	return s.teamRequest(t), nil
+}
+
+func (r *teamRepository) RemoveMembers(ctx context.Context, teamName string, userIDs []string, selectReviewers domain.SelectReviewers) (domain.TeamRequest, []domain.Reassignment, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamName]
+	if !ok {
+		return domain.TeamRequest{}, nil, domain.ErrNotFound
+	}
+
+	members, err := t.leave(userIDs)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	reassignments := []domain.Reassignment{}
+	for _, userID := range members {
+		reassignments = append(reassignments, s.releaseReviews(ctx, userID, domain.ReasonMemberRemoved, t, selectReviewers)...)
+	}
+
+	return s.teamRequest(t), reassignments, nil
+}
+
+func (r *teamRepository) MoveMember(ctx context.Context, userID, fromTeam, toTeam string, selectReviewers domain.SelectReviewers) (domain.TeamRequest, []domain.Reassignment, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, ok := s.teams[fromTeam]
+	if !ok {
+		return domain.TeamRequest{}, nil, domain.ErrNotFound
+	}
+
+	to, ok := s.teams[toTeam]
+	if !ok {
+		return domain.TeamRequest{}, nil, domain.ErrNotFound
+	}
+
+	if _, err := from.leave([]string{userID}); err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	if !slices.Contains(to.members, userID) {
+		to.members = append(to.members, userID)
+	}
+
+	reassignments := s.releaseReviews(ctx, userID, domain.ReasonMemberMoved, from, selectReviewers)
+
+	return s.teamRequest(to), reassignments, nil
+}
+
+func (r *teamRepository) ListTeams(ctx context.Context) ([]domain.TeamSummary, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	teams := []domain.TeamSummary{}
+	for _, name := range slices.Sorted(maps.Keys(s.teams)) {
+		summary := domain.TeamSummary{TeamName: name}
+		for _, userID := range s.teams[name].members {
+			summary.MembersCount++
+			if s.users[userID].IsActive {
+				summary.ActiveMembers++
+			}
+		}
+
+		teams = append(teams, summary)
+	}
+
+	return teams, nil
+}
+
+func (r *teamRepository) RenameTeam(ctx context.Context, teamName, newName string) (domain.TeamRequest, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamName]
+	if !ok {
+		return domain.TeamRequest{}, domain.ErrNotFound
+	}
+	if _, ok := s.teams[newName]; ok {
+		return domain.TeamRequest{}, domain.ErrTeamExists
+	}
+
+	delete(s.teams, teamName)
+	s.teams[newName] = t
+	t.settings.TeamName = newName
+
+	// API keys refer to the team by id in the databases, so they follow it.
+	for _, key := range s.apiKeys {
+		if key.TeamName == teamName {
+			key.TeamName = newName
+		}
+	}
+
+	return s.teamRequest(t), nil
+}
+
+func (r *teamRepository) DeleteTeam(ctx context.Context, teamName string, force bool, reassignTo string, selectReviewers domain.SelectReviewers) (domain.DeleteTeamResponse, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamName]
+	if !ok {
+		return domain.DeleteTeamResponse{}, domain.ErrNotFound
+	}
+
+	var to *team
+	if reassignTo != "" {
+		if to, ok = s.teams[reassignTo]; !ok {
+			return domain.DeleteTeamResponse{}, domain.ErrNotFound
+		}
+	}
+
+	var prIDs []string
+	hasOpen := false
+	for id, pr := range s.prs {
+		if pr.team == t && pr.Status != domain.StatusMerged {
+			prIDs = append(prIDs, id)
+			hasOpen = hasOpen || pr.Status == domain.StatusOpen
+		}
+	}
+	slices.Sort(prIDs)
+
+	if hasOpen && !force {
+		return domain.DeleteTeamResponse{}, domain.ErrTeamHasOpenPRs
+	}
+
+	resp := domain.DeleteTeamResponse{
+		TeamName:      teamName,
+		ClosedPRs:     []string{},
+		ReassignedPRs: []string{},
+		ReassignedTo:  reassignTo,
+		Reassignments: []domain.Reassignment{},
+	}
+
+	for _, prID := range prIDs {
+		pr := s.prs[prID]
+		if to == nil {
+			if pr.Status != domain.StatusClosed {
+				closedAt := now()
+				pr.Status, pr.closedAt = domain.StatusClosed, &closedAt
+				resp.ClosedPRs = append(resp.ClosedPRs, prID)
+			}
+
+			continue
+		}
+
+		pr.team = to
+		for _, reviewerID := range slices.Sorted(slices.Values(pr.Reviewers)) {
+			if pr.Status == domain.StatusOpen && !slices.Contains(to.members, reviewerID) {
+				resp.Reassignments = append(resp.Reassignments, s.releaseReview(ctx, pr, reviewerID, domain.ReasonTeamDeleted, selectReviewers))
+			}
+		}
+		resp.ReassignedPRs = append(resp.ReassignedPRs, prID)
+	}
+
+	for _, pr := range s.prs {
+		if pr.team == t {
+			pr.team = nil
+		}
+	}
+
+	s.apiKeys = slices.DeleteFunc(s.apiKeys, func(key *apiKey) bool {
+		return key.TeamName == teamName
+	})
+	delete(s.teams, teamName)
+
+	return resp, nil
+}
+
+// leave removes the users from the team, as long as all of them are members
+// and somebody stays. It returns them sorted.
+func (t *team) leave(userIDs []string) ([]string, error) {
+	members := []string{}
+	for _, userID := range slices.Sorted(slices.Values(t.members)) {
+		if slices.Contains(userIDs, userID) {
+			members = append(members, userID)
+		}
+	}
+	if len(members) != len(userIDs) {
+		return nil, domain.ErrNotFound
+	}
+	if len(members) == len(t.members) {
+		return nil, domain.ErrTeamEmpty
+	}
+
+	t.members = slices.DeleteFunc(t.members, func(userID string) bool {
+		return slices.Contains(members, userID)
+	})
+
+	return members, nil
+}
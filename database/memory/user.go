@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	userrepo "github.com/dafuqqqyunglean/avito_tech/database/user"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepo(store *Store) userrepo.Repository {
+	return &userRepository{
+		store: store,
+	}
+}
+
+func (r *userRepository) SetActive(ctx context.Context, userID string, isActive bool, selectReviewers domain.SelectReviewers) (domain.SetActiveResponse, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return domain.SetActiveResponse{}, domain.ErrNotFound
+	}
+
+	u.IsActive = isActive
+
+	var resp domain.SetActiveResponse
+	resp.User.UserID = u.ID
+	resp.User.Username = u.Name
+	resp.User.Teams = s.userTeams(userID)
+	resp.User.IsActive = u.IsActive
+	if len(resp.User.Teams) > 0 {
+		resp.User.TeamName = resp.User.Teams[0]
+	}
+
+	if !isActive {
+		resp.Reassignments = s.releaseReviews(ctx, userID, domain.ReasonUserDeactivated, nil, selectReviewers)
+	}
+
+	return resp, nil
+}
+
+func (r *userRepository) DeactivateTeamMembers(ctx context.Context, teamName string, userIDs []string, selectReviewers domain.SelectReviewers) (domain.DeactivateUsersResponse, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamName]
+	if !ok {
+		return domain.DeactivateUsersResponse{}, domain.ErrNotFound
+	}
+
+	members := []string{}
+	for _, userID := range slices.Sorted(slices.Values(t.members)) {
+		if slices.Contains(userIDs, userID) {
+			members = append(members, userID)
+		}
+	}
+	if len(members) != len(userIDs) {
+		return domain.DeactivateUsersResponse{}, domain.ErrNotFound
+	}
+
+	// As in Postgres, the whole batch goes inactive before any review is
+	// released.
+	for _, userID := range members {
+		s.users[userID].IsActive = false
+	}
+
+	resp := domain.DeactivateUsersResponse{
+		TeamName:         teamName,
+		DeactivatedUsers: members,
+		Reassignments:    []domain.Reassignment{},
+		UnderstaffedPRs:  []string{},
+	}
+
+	for _, userID := range members {
+		reassignments := s.releaseReviews(ctx, userID, domain.ReasonTeamDeactivation, nil, selectReviewers)
+
+		for _, reassignment := range reassignments {
+			if reassignment.NewReviewerID == "" && !slices.Contains(resp.UnderstaffedPRs, reassignment.PrID) {
+				resp.UnderstaffedPRs = append(resp.UnderstaffedPRs, reassignment.PrID)
+			}
+		}
+
+		resp.Reassignments = append(resp.Reassignments, reassignments...)
+	}
+
+	return resp, nil
+}
+
+// releaseReviews hands every OPEN review of the user, in PR ID order, to a
+// member of the PR's team or drops the user from the PR when nobody is left.
+// A non-nil only limits the reviews to that team's PRs.
+func (s *Store) releaseReviews(ctx context.Context, userID, reason string, only *team, selectReviewers domain.SelectReviewers) []domain.Reassignment {
+	var prIDs []string
+	for id, pr := range s.prs {
+		if only != nil && pr.team != only {
+			continue
+		}
+
+		if pr.Status == domain.StatusOpen && slices.Contains(pr.Reviewers, userID) {
+			prIDs = append(prIDs, id)
+		}
+	}
+	slices.Sort(prIDs)
+
+	reassignments := []domain.Reassignment{}
+	for _, prID := range prIDs {
+		reassignments = append(reassignments, s.releaseReview(ctx, s.prs[prID], userID, reason, selectReviewers))
+	}
+
+	return reassignments
+}
+
+// releaseReview hands the user's review of pr over to a member of the PR's
+// team, or drops the user from the PR when nobody is left.
+func (s *Store) releaseReview(ctx context.Context, pr *pullRequest, userID, reason string, selectReviewers domain.SelectReviewers) domain.Reassignment {
+	reassignment := domain.Reassignment{
+		PrID:          pr.ID,
+		OldReviewerID: userID,
+	}
+
+	var picked []string
+	if pr.team != nil {
+		picked = selectReviewers(s.replacementPool(pr.team, pr, userID), 1)
+	}
+
+	i := slices.Index(pr.Reviewers, userID)
+	kind := domain.EventUnassigned
+	if len(picked) > 0 {
+		reassignment.NewReviewerID = picked[0]
+		kind = domain.EventReassigned
+		pr.Reviewers[i] = picked[0]
+	} else {
+		pr.Reviewers = slices.Delete(pr.Reviewers, i, i+1)
+	}
+
+	s.record(pr.ID, kind, userID, reassignment.NewReviewerID, domain.ActorFromContext(ctx), reason)
+
+	return reassignment
+}
+
+func (r *userRepository) GetReview(ctx context.Context, userID string) (domain.GetReviewResponse, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	response := domain.GetReviewResponse{
+		UserID:       userID,
+		PullRequests: []domain.CutPullRequest{},
+	}
+
+	for _, pr := range s.prs {
+		if slices.Contains(pr.Reviewers, userID) {
+			response.PullRequests = append(response.PullRequests, domain.CutPullRequest{
+				ID:       pr.ID,
+				Name:     pr.Name,
+				AuthorID: pr.AuthorID,
+				Status:   pr.Status,
+			})
+		}
+	}
+
+	if len(response.PullRequests) == 0 {
+		return domain.GetReviewResponse{}, domain.ErrNotFound
+	}
+
+	slices.SortFunc(response.PullRequests, func(a, b domain.CutPullRequest) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return response, nil
+}
@@ -2,20 +2,30 @@ package pr
 
 import (
 	"context"
-	_ "embed"
+	"embed"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/dafuqqqyunglean/avito_tech/database/assignment"
 	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Repository interface {
-	Create(ctx context.Context, prID, prName, AuthorID string) (domain.CreatePRResponse, error)
+	Create(ctx context.Context, prID, prName, authorID, teamName, status string, selectReviewers domain.SelectReviewers) (domain.CreatePRResponse, error)
+	Get(ctx context.Context, prID string) (domain.PullRequest, error)
+	GetStatus(ctx context.Context, prID string) (string, error)
+	List(ctx context.Context, filter domain.PRFilter) (domain.ListPRResponse, error)
+	SetStatus(ctx context.Context, prID, from, to string, selectReviewers domain.SelectReviewers) (domain.PullRequest, error)
 	SetMerged(ctx context.Context, prID string) (domain.MergePRResponse, error)
-	Reassign(ctx context.Context, prID, userID string) (domain.ReassignPRResponse, error)
+	GetHistory(ctx context.Context, prID string) (domain.PRHistoryResponse, error)
+	Reassign(ctx context.Context, prID, oldUserID string, selectReviewers domain.SelectReviewers) (domain.ReassignPRResponse, error)
 }
 
 type repository struct {
@@ -28,85 +38,95 @@ func NewRepo(db *pgxpool.Pool) Repository {
 	}
 }
 
-//go:embed sql/checkIfPRExists.sql
-var checkIfPRExists string
+//go:embed sql/*.sql
+var queries embed.FS
 
-//go:embed sql/getTeamName.sql
-var getTeamName string
+func init() {
+	tracing.MustRegisterQueries(queries)
+}
 
 //go:embed sql/selectReviewersFromTeam.sql
 var selectReviewersFromTeam string
 
+func queryCandidates(ctx context.Context, q querier, query string, args ...any) ([]domain.ReviewCandidate, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []domain.ReviewCandidate{}
+	for rows.Next() {
+		var candidate domain.ReviewCandidate
+		if err := rows.Scan(&candidate.UserID, &candidate.OpenReviews); err != nil {
+			return nil, fmt.Errorf("scan team member: %w", err)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return candidates, nil
+}
+
+//go:embed sql/lockAuthorTeam.sql
+var lockAuthorTeam string
+
 //go:embed sql/createPullRequest.sql
 var createPullRequest string
 
 //go:embed sql/admitReviewers.sql
 var admitReviewers string
 
-func (r *repository) Create(ctx context.Context, prID, prName, authorID string) (domain.CreatePRResponse, error) {
-	var exists bool
-	err := r.db.QueryRow(ctx, checkIfPRExists, prID).Scan(&exists)
+// Create stores the PR and, unless it is a draft, its reviewers in a single
+// transaction. The PR goes to teamName, or to the author's only team when
+// teamName is empty. That team's row stays locked until commit, so concurrent
+// creates in one team see each other's review load, and a concurrent create
+// of the same PR ID waits for this one and then gets domain.ErrPRExists.
+func (r *repository) Create(ctx context.Context, prID, prName, authorID, teamName, status string, selectReviewers domain.SelectReviewers) (domain.CreatePRResponse, error) {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return domain.CreatePRResponse{}, fmt.Errorf("failed to check PR existence: %w", err)
-	}
-	if exists {
-		return domain.CreatePRResponse{}, domain.ErrPRExists
-	}
-
-	var teamName string
-	err = r.db.QueryRow(ctx, getTeamName, authorID).Scan(&teamName)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return domain.CreatePRResponse{}, domain.ErrNotFound
-	} else if err != nil {
-		return domain.CreatePRResponse{}, fmt.Errorf("failed to get user team: %w", err)
+		return domain.CreatePRResponse{}, fmt.Errorf("failed to create transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
 
-	rows, err := r.db.Query(ctx, selectReviewersFromTeam, authorID, teamName)
+	teamID, pool, err := lockAuthorPool(ctx, tx, authorID, teamName)
 	if err != nil {
-		return domain.CreatePRResponse{}, fmt.Errorf("failed to get reviewers: %w", err)
+		return domain.CreatePRResponse{}, err
 	}
-	defer rows.Close()
 
 	resp := domain.CreatePRResponse{
 		PR: domain.PullRequest{
 			ID:        prID,
 			Name:      prName,
 			AuthorID:  authorID,
-			Status:    "OPEN",
+			TeamName:  pool.TeamName,
+			Status:    status,
 			Reviewers: []string{},
 		},
 	}
 
-	for rows.Next() {
-		var userID string
-		if err := rows.Scan(&userID); err != nil {
-			return domain.CreatePRResponse{}, fmt.Errorf("scan team member: %w", err)
-		}
-
-		resp.PR.Reviewers = append(resp.PR.Reviewers, userID)
-	}
-
-	if err := rows.Err(); err != nil {
-		return domain.CreatePRResponse{}, fmt.Errorf("rows iteration error: %w", err)
-	}
-
-	if len(resp.PR.Reviewers) == 0 {
-		return domain.CreatePRResponse{}, domain.ErrNotFound
+	err = tx.QueryRow(ctx, createPullRequest, prID, prName, authorID, status, teamID).Scan(&resp.PR.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.CreatePRResponse{}, domain.ErrPRExists
 	}
-
-	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return domain.CreatePRResponse{}, fmt.Errorf("failed to create transaction: %w", err)
+		return domain.CreatePRResponse{}, fmt.Errorf("failed to save pr to database: %w", err)
 	}
-	defer tx.Rollback(ctx)
 
-	_, err = tx.Exec(ctx, createPullRequest,
-		resp.PR.ID,
-		resp.PR.Name,
-		resp.PR.AuthorID,
-		resp.PR.Status)
-	if err != nil {
-		return domain.CreatePRResponse{}, fmt.Errorf("failed to save pr to database: %w", err)
+	if status == domain.StatusOpen {
+		pool.Candidates, err = queryCandidates(ctx, tx, selectReviewersFromTeam, authorID, pool.TeamName)
+		if err != nil {
+			return domain.CreatePRResponse{}, fmt.Errorf("failed to get reviewers: %w", err)
+		}
+
+		resp.PR.Reviewers = selectReviewers(pool, pool.MaxReviewers)
+		if len(resp.PR.Reviewers) < pool.MinReviewers {
+			return domain.CreatePRResponse{}, domain.ErrNotEnoughReviewers
+		}
 	}
 
 	for _, reviewerID := range resp.PR.Reviewers {
@@ -114,7 +134,12 @@ func (r *repository) Create(ctx context.Context, prID, prName, authorID string)
 			resp.PR.ID,
 			reviewerID)
 		if err != nil {
-			return domain.CreatePRResponse{}, fmt.Errorf("failed to assign reviewer %s: %w", reviewerID, err)
+			return domain.CreatePRResponse{}, assignment.ConstraintError(err, "failed to assign reviewer "+reviewerID)
+		}
+
+		err = assignment.RecordEvent(ctx, tx, resp.PR.ID, domain.EventAssigned, "", reviewerID, domain.ReasonPRCreated)
+		if err != nil {
+			return domain.CreatePRResponse{}, err
 		}
 	}
 
@@ -125,148 +150,446 @@ func (r *repository) Create(ctx context.Context, prID, prName, authorID string)
 	return resp, nil
 }
 
+// lockAuthorPool locks the author's team the PR is created in. An empty
+// teamName matches every team of the author, which is ambiguous for authors in
+// more than one.
+func lockAuthorPool(ctx context.Context, tx pgx.Tx, authorID, teamName string) (int, domain.ReviewerPool, error) {
+	rows, err := tx.Query(ctx, lockAuthorTeam, authorID, nullable(teamName))
+	if err != nil {
+		return 0, domain.ReviewerPool{}, fmt.Errorf("failed to lock author team: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		teamID int
+		pool   domain.ReviewerPool
+		found  int
+	)
+	for rows.Next() {
+		if err := rows.Scan(&teamID, &pool.TeamName, &pool.Strategy, &pool.MinReviewers, &pool.MaxReviewers); err != nil {
+			return 0, domain.ReviewerPool{}, fmt.Errorf("scan author team: %w", err)
+		}
+
+		found++
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, domain.ReviewerPool{}, fmt.Errorf("failed to lock author team: %w", err)
+	}
+
+	switch found {
+	case 0:
+		return 0, domain.ReviewerPool{}, domain.ErrNotFound
+	case 1:
+		return teamID, pool, nil
+	default:
+		return 0, domain.ReviewerPool{}, domain.ErrAmbiguousTeam
+	}
+}
+
 //go:embed sql/getPRStatus.sql
 var getPRStatus string
 
-//go:embed sql/setMergedStatus.sql
-var setMergedStatus string
-
 //go:embed sql/getPR.sql
 var getPR string
 
-func (r *repository) SetMerged(ctx context.Context, prID string) (domain.MergePRResponse, error) {
-	var status string
+func (r *repository) Get(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return r.loadPR(ctx, r.db, prID)
+}
 
+func (r *repository) GetStatus(ctx context.Context, prID string) (string, error) {
+	var status string
 	err := r.db.QueryRow(ctx, getPRStatus, prID).Scan(&status)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return domain.MergePRResponse{}, domain.ErrNotFound
+		return "", domain.ErrNotFound
 	}
 	if err != nil {
-		return domain.MergePRResponse{}, fmt.Errorf("failed to get pr status %s: %w", prID, err)
+		return "", fmt.Errorf("failed to get pr status %s: %w", prID, err)
 	}
 
-	now := time.Now()
+	return status, nil
+}
 
-	if status != "MERGED" {
-		_, err := r.db.Exec(ctx, setMergedStatus, prID, now)
+//go:embed sql/listPullRequests.sql
+var listPullRequests string
+
+func (r *repository) List(ctx context.Context, filter domain.PRFilter) (domain.ListPRResponse, error) {
+	var (
+		cursorCreatedAt *time.Time
+		cursorID        *string
+	)
+
+	if filter.Cursor != "" {
+		createdAt, id, err := DecodeCursor(filter.Cursor)
 		if err != nil {
-			return domain.MergePRResponse{}, fmt.Errorf("failed to set pr status = merged %s: %w", prID, err)
+			return domain.ListPRResponse{}, domain.ErrBadRequest
 		}
 
-		status = "MERGED"
+		cursorCreatedAt, cursorID = &createdAt, &id
 	}
 
-	rows, err := r.db.Query(ctx, getPR, prID)
+	rows, err := r.db.Query(ctx, listPullRequests,
+		nullable(filter.AuthorID),
+		nullable(filter.ReviewerID),
+		nullable(filter.TeamName),
+		nullable(filter.Status),
+		filter.CreatedFrom,
+		filter.CreatedTo,
+		filter.MergedFrom,
+		filter.MergedTo,
+		cursorCreatedAt,
+		cursorID,
+		filter.Limit+1)
 	if err != nil {
-		return domain.MergePRResponse{}, fmt.Errorf("failed to load merged pr %s: %w", prID, err)
+		return domain.ListPRResponse{}, fmt.Errorf("failed to list pull requests: %w", err)
 	}
 	defer rows.Close()
 
-	resp := domain.MergePRResponse{
-		PR: domain.PullRequest{
-			ID:        prID,
-			Reviewers: []string{},
-		},
+	resp := domain.ListPRResponse{
+		PullRequests: []domain.PullRequest{},
 	}
 
 	for rows.Next() {
 		var (
-			name       string
-			authorID   string
-			prStatus   string
-			reviewerID string
-			dbMergedAt time.Time
+			pr       domain.PullRequest
+			teamName *string
 		)
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &teamName, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.Reviewers); err != nil {
+			return domain.ListPRResponse{}, fmt.Errorf("scan pull request error: %w", err)
+		}
 
-		if err := rows.Scan(&name, &authorID, &prStatus, &reviewerID, &dbMergedAt); err != nil {
-			return domain.MergePRResponse{}, fmt.Errorf("scan pull request error: %w", err)
+		if teamName != nil {
+			pr.TeamName = *teamName
 		}
 
-		resp.PR.Name = name
-		resp.PR.AuthorID = authorID
-		resp.PR.Status = prStatus
-		resp.PR.Reviewers = append(resp.PR.Reviewers, reviewerID)
-		resp.MergedAt = dbMergedAt
+		resp.PullRequests = append(resp.PullRequests, pr)
 	}
 
 	if err := rows.Err(); err != nil {
-		return domain.MergePRResponse{}, fmt.Errorf("rows iteration error: %w", err)
+		return domain.ListPRResponse{}, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	if len(resp.PR.Reviewers) == 0 {
-		return domain.MergePRResponse{}, domain.ErrNotFound
+	if len(resp.PullRequests) > filter.Limit {
+		resp.PullRequests = resp.PullRequests[:filter.Limit]
+
+		last := resp.PullRequests[len(resp.PullRequests)-1]
+		resp.NextCursor = EncodeCursor(last.CreatedAt, last.ID)
 	}
 
 	return resp, nil
 }
 
-//go:embed sql/reassignReviewer.sql
-var reassignReviewer string
+// EncodeCursor builds the list cursor from the (created_at, id) key of the last
+// returned PR, encoded so that clients treat it as an opaque token. Every
+// storage backend pages with the same cursor format.
+func EncodeCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.Format(time.RFC3339Nano) + "|" + id))
+}
 
-//go:embed sql/getReassignPR.sql
-var getReassignPR string
+func DecodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("failed to decode cursor: %w", err)
+	}
 
-func (r *repository) Reassign(ctx context.Context, prID, userID string) (domain.ReassignPRResponse, error) {
-	var status string
-	err := r.db.QueryRow(ctx, getPRStatus, prID).Scan(&status)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return domain.ReassignPRResponse{}, domain.ErrNotFound
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("malformed cursor")
 	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
 	if err != nil {
-		return domain.ReassignPRResponse{}, fmt.Errorf("failed to get pr status: %w", err)
+		return time.Time{}, "", fmt.Errorf("malformed cursor time: %w", err)
 	}
-	if status == "MERGED" {
-		return domain.ReassignPRResponse{}, domain.ErrPRMerged
+
+	return t, id, nil
+}
+
+func nullable(value string) *string {
+	if value == "" {
+		return nil
 	}
 
-	var newID string
-	err = r.db.QueryRow(ctx, reassignReviewer, prID, userID).Scan(&newID)
+	return &value
+}
+
+//go:embed sql/updatePRStatus.sql
+var updatePRStatus string
+
+// SetStatus moves the PR from one status to another with its row locked for
+// the whole transaction, as in Reassign. A PR that becomes OPEN without
+// reviewers gets them from its team, picked by selectReviewers as in Create.
+func (r *repository) SetStatus(ctx context.Context, prID, from, to string, selectReviewers domain.SelectReviewers) (domain.PullRequest, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := assignment.LockPR(ctx, tx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	if status != from {
+		return domain.PullRequest{}, domain.ErrInvalidTransition
+	}
+
+	pr, err := r.loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	reviewers := []string{}
+	if to == domain.StatusOpen && len(pr.Reviewers) == 0 {
+		// The PR row is locked, so a missing pool means the PR has no team.
+		pool, authorID, err := assignment.Pool(ctx, tx, prID)
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.PullRequest{}, domain.ErrPRHasNoTeam
+		}
+		if err != nil {
+			return domain.PullRequest{}, err
+		}
+
+		pool.Candidates, err = queryCandidates(ctx, tx, selectReviewersFromTeam, authorID, pool.TeamName)
+		if err != nil {
+			return domain.PullRequest{}, fmt.Errorf("failed to get reviewers: %w", err)
+		}
+
+		reviewers = selectReviewers(pool, pool.MaxReviewers)
+		if len(reviewers) < pool.MinReviewers {
+			return domain.PullRequest{}, domain.ErrNotEnoughReviewers
+		}
+	}
+
+	var id string
+	err = tx.QueryRow(ctx, updatePRStatus, prID, from, to).Scan(&id)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return domain.ReassignPRResponse{}, domain.ErrNotFound
+		return domain.PullRequest{}, domain.ErrInvalidTransition
 	}
 	if err != nil {
-		return domain.ReassignPRResponse{}, fmt.Errorf("failed to update pr reviewer: %w", err)
+		return domain.PullRequest{}, fmt.Errorf("failed to set pr status = %s %s: %w", to, prID, err)
+	}
+
+	reason := domain.ReasonPRReopened
+	if from == domain.StatusDraft {
+		reason = domain.ReasonPRReady
+	}
+
+	for _, reviewerID := range reviewers {
+		_, err := tx.Exec(ctx, admitReviewers, prID, reviewerID)
+		if err != nil {
+			return domain.PullRequest{}, assignment.ConstraintError(err, "failed to assign reviewer "+reviewerID)
+		}
+
+		if err := assignment.RecordEvent(ctx, tx, prID, domain.EventAssigned, "", reviewerID, reason); err != nil {
+			return domain.PullRequest{}, err
+		}
+	}
+
+	pr, err = r.loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("transaction uncommitted: %w", err)
 	}
 
-	rows, err := r.db.Query(ctx, getReassignPR, prID)
+	return pr, nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (r *repository) loadPR(ctx context.Context, q querier, prID string) (domain.PullRequest, error) {
+	rows, err := q.Query(ctx, getPR, prID)
 	if err != nil {
-		return domain.ReassignPRResponse{}, fmt.Errorf("failed to load pr reviewers: %w", err)
+		return domain.PullRequest{}, fmt.Errorf("failed to load pr %s: %w", prID, err)
 	}
 	defer rows.Close()
 
-	resp := domain.ReassignPRResponse{
-		PR: domain.PullRequest{
-			ID:        prID,
-			Reviewers: []string{},
-		},
-		ReplacedBy: newID,
+	pr := domain.PullRequest{
+		ID:        prID,
+		Reviewers: []string{},
 	}
 
+	var found bool
 	for rows.Next() {
-		var (
-			name       string
-			authorID   string
-			prStatus   string
-			reviewerID string
-		)
+		var teamName, reviewerID *string
+
+		if err := rows.Scan(&pr.Name, &pr.AuthorID, &teamName, &pr.Status, &reviewerID, &pr.MergedAt, &pr.CreatedAt); err != nil {
+			return domain.PullRequest{}, fmt.Errorf("scan pull request error: %w", err)
+		}
+
+		found = true
 
-		if err := rows.Scan(&name, &authorID, &prStatus, &reviewerID); err != nil {
-			return domain.ReassignPRResponse{}, fmt.Errorf("scan pull request error: %w", err)
+		if teamName != nil {
+			pr.TeamName = *teamName
 		}
 
-		resp.PR.Name = name
-		resp.PR.AuthorID = authorID
-		resp.PR.Status = prStatus
-		resp.PR.Reviewers = append(resp.PR.Reviewers, reviewerID)
+		if reviewerID != nil {
+			pr.Reviewers = append(pr.Reviewers, *reviewerID)
+		}
 	}
 
 	if err := rows.Err(); err != nil {
-		return domain.ReassignPRResponse{}, fmt.Errorf("rows iteration error: %w", err)
+		return domain.PullRequest{}, fmt.Errorf("rows iteration error: %w", err)
 	}
 
-	if len(resp.PR.Reviewers) == 0 {
-		return domain.ReassignPRResponse{}, domain.ErrNotFound
+	if !found {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	return pr, nil
+}
+
+//go:embed sql/setMergedStatus.sql
+var setMergedStatus string
+
+func (r *repository) SetMerged(ctx context.Context, prID string) (domain.MergePRResponse, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.MergePRResponse{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := assignment.LockPR(ctx, tx, prID)
+	if err != nil {
+		return domain.MergePRResponse{}, err
+	}
+
+	if status != domain.StatusMerged {
+		tag, err := tx.Exec(ctx, setMergedStatus, prID, time.Now())
+		if err != nil {
+			return domain.MergePRResponse{}, fmt.Errorf("failed to set pr status = merged %s: %w", prID, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.MergePRResponse{}, domain.ErrInvalidTransition
+		}
+
+		err = assignment.RecordEvent(ctx, tx, prID, domain.EventMerged, "", "", domain.ReasonPRMerged)
+		if err != nil {
+			return domain.MergePRResponse{}, err
+		}
+	}
+
+	pr, err := r.loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.MergePRResponse{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.MergePRResponse{}, fmt.Errorf("transaction uncommitted: %w", err)
+	}
+
+	resp := domain.MergePRResponse{PR: pr}
+	if pr.MergedAt != nil {
+		resp.MergedAt = *pr.MergedAt
 	}
 
 	return resp, nil
 }
+
+//go:embed sql/getAssignmentHistory.sql
+var getAssignmentHistory string
+
+func (r *repository) GetHistory(ctx context.Context, prID string) (domain.PRHistoryResponse, error) {
+	if _, err := r.GetStatus(ctx, prID); err != nil {
+		return domain.PRHistoryResponse{}, err
+	}
+
+	rows, err := r.db.Query(ctx, getAssignmentHistory, prID)
+	if err != nil {
+		return domain.PRHistoryResponse{}, fmt.Errorf("failed to get assignment history: %w", err)
+	}
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByPos[domain.AssignmentEvent])
+	if err != nil {
+		return domain.PRHistoryResponse{}, fmt.Errorf("failed to scan assignment history: %w", err)
+	}
+
+	return domain.PRHistoryResponse{
+		PrID:   prID,
+		Events: events,
+	}, nil
+}
+
+//go:embed sql/reassignReviewer.sql
+var reassignReviewer string
+
+// Reassign replaces oldUserID on the PR with a teammate chosen by
+// selectReviewers. The PR row is locked for the whole transaction, so
+// concurrent reassigns of the same PR pick their replacements one after
+// another and a merge cannot slip in between the status check and the update.
+// Nothing is selected unless oldUserID reviews the PR, so a rejected request
+// does not move the round-robin cursor.
+func (r *repository) Reassign(ctx context.Context, prID, oldUserID string, selectReviewers domain.SelectReviewers) (domain.ReassignPRResponse, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.ReassignPRResponse{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := assignment.LockPR(ctx, tx, prID)
+	if err != nil {
+		return domain.ReassignPRResponse{}, err
+	}
+	if status == domain.StatusMerged {
+		return domain.ReassignPRResponse{}, domain.ErrPRMerged
+	}
+	if status != domain.StatusOpen {
+		return domain.ReassignPRResponse{}, domain.ErrInvalidTransition
+	}
+
+	pr, err := r.loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.ReassignPRResponse{}, err
+	}
+	if !slices.Contains(pr.Reviewers, oldUserID) {
+		return domain.ReassignPRResponse{}, domain.ErrNotFound
+	}
+
+	pool, _, err := assignment.Pool(ctx, tx, prID)
+	if err != nil {
+		return domain.ReassignPRResponse{}, err
+	}
+
+	pool.Candidates, err = assignment.Candidates(ctx, tx, prID, oldUserID, pool.TeamName)
+	if err != nil {
+		return domain.ReassignPRResponse{}, err
+	}
+
+	replacement := selectReviewers(pool, 1)
+	if len(replacement) == 0 {
+		return domain.ReassignPRResponse{}, domain.ErrNoCandidate
+	}
+
+	var newID string
+	err = tx.QueryRow(ctx, reassignReviewer, prID, oldUserID, replacement[0]).Scan(&newID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.ReassignPRResponse{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.ReassignPRResponse{}, assignment.ConstraintError(err, "failed to update pr reviewer")
+	}
+
+	err = assignment.RecordEvent(ctx, tx, prID, domain.EventReassigned, oldUserID, newID, domain.ReasonManualReassign)
+	if err != nil {
+		return domain.ReassignPRResponse{}, err
+	}
+
+	pr, err = r.loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.ReassignPRResponse{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.ReassignPRResponse{}, fmt.Errorf("transaction uncommitted: %w", err)
+	}
+
+	return domain.ReassignPRResponse{
+		PR:         pr,
+		ReplacedBy: newID,
+	}, nil
+}
@@ -1 +0,0 @@
-SELECT EXISTS(SELECT 1 FROM pull_requests WHERE id = $1)
\ No newline at end of file
@@ -1,3 +1,4 @@
-INSERT INTO pull_requests (id, name, author_id, status)
-VALUES ($1, $2, $3, $4)
-ON CONFLICT (id) DO NOTHING;
\ No newline at end of file
+INSERT INTO pull_requests (id, name, author_id, status, team_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (id) DO NOTHING
+RETURNING created_at;
\ No newline at end of file
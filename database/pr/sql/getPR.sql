@@ -1,4 +1,5 @@
-SELECT pr.name, pr.author_id, pr.status, rv.user_id, pr.merged_at
+SELECT pr.name, pr.author_id, t.name, pr.status, rv.user_id, pr.merged_at, pr.created_at
 FROM pull_requests pr
-JOIN pr_reviewers rv ON pr.id = rv.pr_id
-WHERE pr.id = $1;
\ No newline at end of file
+LEFT JOIN teams t ON t.id = pr.team_id
+LEFT JOIN pr_reviewers rv ON pr.id = rv.pr_id
+WHERE pr.id = $1;
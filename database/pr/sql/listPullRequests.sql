@@ -0,0 +1,22 @@
+SELECT pr.id, pr.name, pr.author_id, t.name, pr.status, pr.created_at, pr.merged_at,
+       COALESCE(ARRAY_AGG(rv.user_id ORDER BY rv.user_id) FILTER (WHERE rv.user_id IS NOT NULL), '{}') AS reviewers
+FROM pull_requests pr
+LEFT JOIN teams t ON t.id = pr.team_id
+LEFT JOIN pr_reviewers rv ON rv.pr_id = pr.id
+WHERE ($1::VARCHAR IS NULL OR pr.author_id = $1)
+  AND ($2::VARCHAR IS NULL OR EXISTS (
+      SELECT 1
+      FROM pr_reviewers f
+      WHERE f.pr_id = pr.id
+        AND f.user_id = $2
+  ))
+  AND ($3::VARCHAR IS NULL OR t.name = $3)
+  AND ($4::VARCHAR IS NULL OR pr.status = $4)
+  AND ($5::TIMESTAMP IS NULL OR pr.created_at >= $5)
+  AND ($6::TIMESTAMP IS NULL OR pr.created_at < $6)
+  AND ($7::TIMESTAMP IS NULL OR pr.merged_at >= $7)
+  AND ($8::TIMESTAMP IS NULL OR pr.merged_at < $8)
+  AND ($9::TIMESTAMP IS NULL OR (pr.created_at, pr.id) < ($9, $10::VARCHAR))
+GROUP BY pr.id, t.id
+ORDER BY pr.created_at DESC, pr.id DESC
+LIMIT $11;
@@ -0,0 +1,7 @@
+SELECT t.id, t.name, t.assignment_strategy, t.min_reviewers, t.max_reviewers
+FROM teams t
+JOIN team_members tm ON t.id = tm.team_id
+WHERE tm.user_id = $1
+  AND ($2::VARCHAR IS NULL OR t.name = $2)
+ORDER BY t.id
+FOR UPDATE OF t;
\ No newline at end of file
@@ -1,3 +1,4 @@
 UPDATE pull_requests
 SET status = 'MERGED', merged_at = $2
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1
+  AND status = 'OPEN';
@@ -0,0 +1,6 @@
+UPDATE pull_requests
+SET status = $3::VARCHAR,
+    closed_at = CASE WHEN $3::VARCHAR = 'CLOSED' THEN NOW() ELSE NULL END
+WHERE id = $1
+  AND status = $2
+RETURNING id;
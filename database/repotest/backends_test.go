@@ -0,0 +1,86 @@
+package repotest_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dafuqqqyunglean/avito_tech/database/memory"
+	prrepo "github.com/dafuqqqyunglean/avito_tech/database/pr"
+	"github.com/dafuqqqyunglean/avito_tech/database/repotest"
+	"github.com/dafuqqqyunglean/avito_tech/database/sqlite"
+	statsrepo "github.com/dafuqqqyunglean/avito_tech/database/stats"
+	teamrepo "github.com/dafuqqqyunglean/avito_tech/database/team"
+	userrepo "github.com/dafuqqqyunglean/avito_tech/database/user"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+// testDatabaseURLEnv points the Postgres run at a disposable database; it is
+// skipped when the variable is not set.
+const testDatabaseURLEnv = "TEST_DATABASE_URL"
+
+func TestMemory(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		store := memory.NewStore()
+
+		return repotest.Repos{
+			PR:    memory.NewPRRepo(store),
+			Team:  memory.NewTeamRepo(store),
+			User:  memory.NewUserRepo(store),
+			Stats: memory.NewStatsRepo(store),
+		}
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	db, err := sqlite.Open(filepath.Join(t.TempDir(), "conformance.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := sqlite.Migrate(context.Background(), db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		return repotest.Repos{
+			PR:    sqlite.NewPRRepo(db),
+			Team:  sqlite.NewTeamRepo(db),
+			User:  sqlite.NewUserRepo(db),
+			Stats: sqlite.NewStatsRepo(db),
+		}
+	})
+}
+
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv(testDatabaseURLEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseURLEnv)
+	}
+
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+
+	if err := goose.Up(db, "../../migrations"); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	repotest.Run(t, func(t *testing.T) repotest.Repos {
+		return repotest.Repos{
+			PR:    prrepo.NewRepo(pool),
+			Team:  teamrepo.NewRepo(pool),
+			User:  userrepo.NewRepo(pool),
+			Stats: statsrepo.NewRepo(pool),
+		}
+	})
+}
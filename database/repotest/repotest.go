@@ -0,0 +1,1115 @@
+// Package repotest is the conformance suite every storage backend must pass. It
+// drives the repositories only through their interfaces, so one set of
+// expectations covers Postgres, SQLite and the in-memory store alike.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	prrepo "github.com/dafuqqqyunglean/avito_tech/database/pr"
+	statsrepo "github.com/dafuqqqyunglean/avito_tech/database/stats"
+	teamrepo "github.com/dafuqqqyunglean/avito_tech/database/team"
+	userrepo "github.com/dafuqqqyunglean/avito_tech/database/user"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+const actor = "conformance"
+
+// Repos are the repositories of one backend, all backed by the same storage.
+type Repos struct {
+	PR    prrepo.Repository
+	Team  teamrepo.Repository
+	User  userrepo.Repository
+	Stats statsrepo.Repository
+}
+
+// Run runs the suite. newRepos is called once per test; every test works with
+// its own IDs, so backends may share one database between tests.
+func Run(t *testing.T, newRepos func(t *testing.T) Repos) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, f *fixture)
+	}{
+		{"CreateAndGetTeam", testCreateAndGetTeam},
+		{"TeamSettings", testTeamSettings},
+		{"CreateAssignsActiveTeammates", testCreateAssignsActiveTeammates},
+		{"CreateErrors", testCreateErrors},
+		{"DraftLifecycle", testDraftLifecycle},
+		{"MergeIsIdempotent", testMergeIsIdempotent},
+		{"Reassign", testReassign},
+		{"List", testList},
+		{"SetActiveReleasesReviews", testSetActiveReleasesReviews},
+		{"DeactivateTeamMembers", testDeactivateTeamMembers},
+		{"GetReview", testGetReview},
+		{"AddMembers", testAddMembers},
+		{"RemoveMembers", testRemoveMembers},
+		{"MoveMember", testMoveMember},
+		{"MultipleTeams", testMultipleTeams},
+		{"AssignmentStats", testAssignmentStats},
+		{"ListTeams", testListTeams},
+		{"RenameTeam", testRenameTeam},
+		{"DeleteTeam", testDeleteTeam},
+		{"CreateSamePRConcurrently", testCreateSamePRConcurrently},
+		{"ReassignAndMergeConcurrently", testReassignAndMergeConcurrently},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, &fixture{
+				Repos:  newRepos(t),
+				ctx:    domain.WithActor(context.Background(), actor),
+				suffix: fmt.Sprintf("%08x", rand.Uint32()),
+			})
+		})
+	}
+}
+
+type fixture struct {
+	Repos
+	ctx    context.Context
+	suffix string
+}
+
+// id makes name unique to the running test.
+func (f *fixture) id(name string) string {
+	return name + "-" + f.suffix
+}
+
+// team creates a team of active users with the given names and returns their
+// IDs in the same order.
+func (f *fixture) team(t *testing.T, name string, userNames ...string) []string {
+	t.Helper()
+
+	ids := make([]string, len(userNames))
+	members := make([]domain.User, len(userNames))
+	for i, userName := range userNames {
+		ids[i] = f.id(userName)
+		members[i] = domain.User{ID: ids[i], Name: ids[i], IsActive: true}
+	}
+
+	if _, err := f.Team.CreateTeam(f.ctx, f.id(name), domain.StrategyLeastLoaded, members); err != nil {
+		t.Fatalf("create team %s: %v", name, err)
+	}
+
+	return ids
+}
+
+func (f *fixture) createPR(t *testing.T, name, authorID, status string) domain.PullRequest {
+	t.Helper()
+
+	resp, err := f.PR.Create(f.ctx, f.id(name), name, authorID, "", status, selectFirst)
+	if err != nil {
+		t.Fatalf("create %s: %v", name, err)
+	}
+
+	return resp.PR
+}
+
+func (f *fixture) history(t *testing.T, prID string) []domain.AssignmentEvent {
+	t.Helper()
+
+	resp, err := f.PR.GetHistory(f.ctx, prID)
+	if err != nil {
+		t.Fatalf("history of %s: %v", prID, err)
+	}
+
+	return resp.Events
+}
+
+// selectFirst picks candidates in the order the repository lists them, which
+// is by user ID, so expectations do not depend on a strategy.
+func selectFirst(pool domain.ReviewerPool, count int) []string {
+	ids := []string{}
+	for _, candidate := range pool.Candidates[:min(count, len(pool.Candidates))] {
+		ids = append(ids, candidate.UserID)
+	}
+
+	return ids
+}
+
+// selectRandom shuffles the candidates, so concurrent callers are likely to
+// pick the same reviewer and exercise the backend's conflict handling.
+func selectRandom(pool domain.ReviewerPool, count int) []string {
+	candidates := slices.Clone(pool.Candidates)
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	return selectFirst(domain.ReviewerPool{Candidates: candidates}, count)
+}
+
+func sorted(ids []string) []string {
+	return slices.Sorted(slices.Values(ids))
+}
+
+func expectErr(t *testing.T, op string, err, want error) {
+	t.Helper()
+
+	if !errors.Is(err, want) {
+		t.Fatalf("%s: got error %v, want %v", op, err, want)
+	}
+}
+
+func expectIDs(t *testing.T, what string, got, want []string) {
+	t.Helper()
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("%s: got %v, want %v", what, got, want)
+	}
+}
+
+func expectEvent(t *testing.T, got domain.AssignmentEvent, kind, oldID, newID, reason string) {
+	t.Helper()
+
+	deref := func(id *string) string {
+		if id == nil {
+			return ""
+		}
+		return *id
+	}
+
+	if got.Event != kind || deref(got.OldReviewerID) != oldID || deref(got.NewReviewerID) != newID ||
+		got.Reason != reason || got.Actor != actor {
+		t.Fatalf("event: got %s %q -> %q (%s by %s), want %s %q -> %q (%s by %s)",
+			got.Event, deref(got.OldReviewerID), deref(got.NewReviewerID), got.Reason, got.Actor,
+			kind, oldID, newID, reason, actor)
+	}
+}
+
+func testCreateAndGetTeam(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "u2", "u1", "u3")
+
+	team, err := f.Team.GetTeam(f.ctx, f.id("team"))
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+
+	var members []string
+	for _, member := range team.Members {
+		members = append(members, member.ID)
+	}
+	expectIDs(t, "members", members, sorted(ids))
+
+	if team.AssignmentStrategy != domain.StrategyLeastLoaded {
+		t.Fatalf("strategy: got %q, want %q", team.AssignmentStrategy, domain.StrategyLeastLoaded)
+	}
+
+	_, err = f.Team.CreateTeam(f.ctx, f.id("team"), domain.StrategyRandom, nil)
+	expectErr(t, "create existing team", err, domain.ErrTeamExists)
+
+	_, err = f.Team.GetTeam(f.ctx, f.id("missing"))
+	expectErr(t, "get missing team", err, domain.ErrNotFound)
+}
+
+func testTeamSettings(t *testing.T, f *fixture) {
+	f.team(t, "team", "u1")
+
+	settings, err := f.Team.GetSettings(f.ctx, f.id("team"))
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	if settings.MinReviewers != 1 || settings.MaxReviewers != 2 {
+		t.Fatalf("default limits: got %d..%d, want 1..2", settings.MinReviewers, settings.MaxReviewers)
+	}
+
+	want := domain.TeamSettings{
+		TeamName:           f.id("team"),
+		AssignmentStrategy: domain.StrategyRoundRobin,
+		MinReviewers:       0,
+		MaxReviewers:       3,
+	}
+	if updated, err := f.Team.UpdateSettings(f.ctx, want); err != nil || updated != want {
+		t.Fatalf("update settings: got %+v, %v, want %+v", updated, err, want)
+	}
+	if settings, err := f.Team.GetSettings(f.ctx, f.id("team")); err != nil || settings != want {
+		t.Fatalf("get updated settings: got %+v, %v, want %+v", settings, err, want)
+	}
+
+	_, err = f.Team.GetSettings(f.ctx, f.id("missing"))
+	expectErr(t, "get missing settings", err, domain.ErrNotFound)
+
+	want.TeamName = f.id("missing")
+	_, err = f.Team.UpdateSettings(f.ctx, want)
+	expectErr(t, "update missing settings", err, domain.ErrNotFound)
+}
+
+func testCreateAssignsActiveTeammates(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2", "r3", "r4")
+	author, r1, r2, r3, r4 := ids[0], ids[1], ids[2], ids[3], ids[4]
+
+	if _, err := f.User.SetActive(f.ctx, r1, false, selectFirst); err != nil {
+		t.Fatalf("deactivate r1: %v", err)
+	}
+
+	pr := f.createPR(t, "pr", author, domain.StatusOpen)
+	expectIDs(t, "reviewers", pr.Reviewers, []string{r2, r3})
+	if pr.Status != domain.StatusOpen || pr.CreatedAt.IsZero() {
+		t.Fatalf("created pr: got status %s at %v", pr.Status, pr.CreatedAt)
+	}
+
+	events := f.history(t, pr.ID)
+	if len(events) != 2 {
+		t.Fatalf("history: got %d events, want 2", len(events))
+	}
+	expectEvent(t, events[0], domain.EventAssigned, "", r2, domain.ReasonPRCreated)
+	expectEvent(t, events[1], domain.EventAssigned, "", r3, domain.ReasonPRCreated)
+
+	draft := f.createPR(t, "draft", author, domain.StatusDraft)
+
+	var pool domain.ReviewerPool
+	capture := func(p domain.ReviewerPool, n int) []string {
+		pool = p
+		return selectFirst(p, n)
+	}
+	if _, err := f.PR.SetStatus(f.ctx, draft.ID, domain.StatusDraft, domain.StatusOpen, capture); err != nil {
+		t.Fatalf("ready draft: %v", err)
+	}
+	want := []domain.ReviewCandidate{{UserID: r2, OpenReviews: 1}, {UserID: r3, OpenReviews: 1}, {UserID: r4, OpenReviews: 0}}
+	if pool.TeamName != f.id("team") || pool.MinReviewers != 1 || pool.MaxReviewers != 2 || !slices.Equal(pool.Candidates, want) {
+		t.Fatalf("reviewer pool: got %+v, want candidates %+v", pool, want)
+	}
+}
+
+func testCreateErrors(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1")
+	author := ids[0]
+	solo := f.team(t, "solo", "s")[0]
+
+	f.createPR(t, "pr", author, domain.StatusOpen)
+
+	_, err := f.PR.Create(f.ctx, f.id("pr"), "again", author, "", domain.StatusOpen, selectFirst)
+	expectErr(t, "create existing pr", err, domain.ErrPRExists)
+
+	_, err = f.PR.Create(f.ctx, f.id("pr-x"), "x", f.id("nobody"), "", domain.StatusOpen, selectFirst)
+	expectErr(t, "create pr of unknown author", err, domain.ErrNotFound)
+
+	_, err = f.PR.Create(f.ctx, f.id("pr-solo"), "solo", solo, "", domain.StatusOpen, selectFirst)
+	expectErr(t, "create pr without reviewers", err, domain.ErrNotEnoughReviewers)
+
+	_, err = f.PR.Get(f.ctx, f.id("pr-solo"))
+	expectErr(t, "get rejected pr", err, domain.ErrNotFound)
+}
+
+func testDraftLifecycle(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1")
+	author, r1 := ids[0], ids[1]
+
+	pr := f.createPR(t, "pr", author, domain.StatusDraft)
+	if pr.Status != domain.StatusDraft || len(pr.Reviewers) != 0 {
+		t.Fatalf("draft: got status %s with reviewers %v", pr.Status, pr.Reviewers)
+	}
+	if events := f.history(t, pr.ID); len(events) != 0 {
+		t.Fatalf("draft history: got %d events, want none", len(events))
+	}
+
+	_, err := f.PR.SetStatus(f.ctx, pr.ID, domain.StatusOpen, domain.StatusClosed, selectFirst)
+	expectErr(t, "transition from stale status", err, domain.ErrInvalidTransition)
+
+	_, err = f.PR.SetStatus(f.ctx, f.id("missing"), domain.StatusDraft, domain.StatusOpen, selectFirst)
+	expectErr(t, "transition of missing pr", err, domain.ErrNotFound)
+
+	none := func(domain.ReviewerPool, int) []string { return nil }
+	_, err = f.PR.SetStatus(f.ctx, pr.ID, domain.StatusDraft, domain.StatusOpen, none)
+	expectErr(t, "ready without reviewers", err, domain.ErrNotEnoughReviewers)
+	if status, err := f.PR.GetStatus(f.ctx, pr.ID); err != nil || status != domain.StatusDraft {
+		t.Fatalf("status after failed ready: got %s, %v", status, err)
+	}
+
+	ready, err := f.PR.SetStatus(f.ctx, pr.ID, domain.StatusDraft, domain.StatusOpen, selectFirst)
+	if err != nil {
+		t.Fatalf("ready: %v", err)
+	}
+	if ready.Status != domain.StatusOpen {
+		t.Fatalf("ready: got status %s", ready.Status)
+	}
+	expectIDs(t, "reviewers", ready.Reviewers, []string{r1})
+	expectEvent(t, f.history(t, pr.ID)[0], domain.EventAssigned, "", r1, domain.ReasonPRReady)
+
+	if _, err := f.PR.SetStatus(f.ctx, pr.ID, domain.StatusOpen, domain.StatusClosed, selectFirst); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if status, err := f.PR.GetStatus(f.ctx, pr.ID); err != nil || status != domain.StatusClosed {
+		t.Fatalf("status after close: got %s, %v", status, err)
+	}
+
+	_, err = f.PR.SetMerged(f.ctx, pr.ID)
+	expectErr(t, "merge closed pr", err, domain.ErrInvalidTransition)
+
+	_, err = f.PR.Reassign(f.ctx, pr.ID, r1, selectFirst)
+	expectErr(t, "reassign on closed pr", err, domain.ErrInvalidTransition)
+
+	_, err = f.PR.GetStatus(f.ctx, f.id("missing"))
+	expectErr(t, "status of missing pr", err, domain.ErrNotFound)
+}
+
+func testMergeIsIdempotent(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2")
+	pr := f.createPR(t, "pr", ids[0], domain.StatusOpen)
+
+	first, err := f.PR.SetMerged(f.ctx, pr.ID)
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if first.PR.Status != domain.StatusMerged || first.PR.MergedAt == nil || !first.MergedAt.Equal(*first.PR.MergedAt) {
+		t.Fatalf("merge: got %+v", first)
+	}
+
+	second, err := f.PR.SetMerged(f.ctx, pr.ID)
+	if err != nil {
+		t.Fatalf("merge again: %v", err)
+	}
+	if !second.MergedAt.Equal(first.MergedAt) {
+		t.Fatalf("merge again moved merged_at from %v to %v", first.MergedAt, second.MergedAt)
+	}
+
+	events := f.history(t, pr.ID)
+	merges := 0
+	for _, event := range events {
+		if event.Event == domain.EventMerged {
+			merges++
+		}
+	}
+	if merges != 1 {
+		t.Fatalf("history: got %d merge events, want 1", merges)
+	}
+	expectEvent(t, events[len(events)-1], domain.EventMerged, "", "", domain.ReasonPRMerged)
+
+	_, err = f.PR.Reassign(f.ctx, pr.ID, pr.Reviewers[0], selectFirst)
+	expectErr(t, "reassign on merged pr", err, domain.ErrPRMerged)
+
+	_, err = f.PR.SetMerged(f.ctx, f.id("missing"))
+	expectErr(t, "merge missing pr", err, domain.ErrNotFound)
+}
+
+func testReassign(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2", "r3", "r4")
+	author, r1, r2, r3 := ids[0], ids[1], ids[2], ids[3]
+
+	pr := f.createPR(t, "pr", author, domain.StatusOpen)
+	expectIDs(t, "reviewers", pr.Reviewers, []string{r1, r2})
+
+	resp, err := f.PR.Reassign(f.ctx, pr.ID, r1, selectFirst)
+	if err != nil {
+		t.Fatalf("reassign: %v", err)
+	}
+	if resp.ReplacedBy != r3 {
+		t.Fatalf("reassign: replaced by %s, want %s", resp.ReplacedBy, r3)
+	}
+	expectIDs(t, "reviewers", sorted(resp.PR.Reviewers), []string{r2, r3})
+
+	events := f.history(t, pr.ID)
+	expectEvent(t, events[len(events)-1], domain.EventReassigned, r1, r3, domain.ReasonManualReassign)
+
+	selected := 0
+	counting := func(pool domain.ReviewerPool, n int) []string {
+		selected++
+		return selectFirst(pool, n)
+	}
+	_, err = f.PR.Reassign(f.ctx, pr.ID, r1, counting)
+	expectErr(t, "reassign a non-reviewer", err, domain.ErrNotFound)
+	if selected != 0 {
+		t.Fatalf("reassign a non-reviewer: selected %d times, want none", selected)
+	}
+
+	_, err = f.PR.Reassign(f.ctx, f.id("missing"), r1, selectFirst)
+	expectErr(t, "reassign on missing pr", err, domain.ErrNotFound)
+
+	pair := f.team(t, "pair", "b", "p1")
+	pairPR := f.createPR(t, "pr-pair", pair[0], domain.StatusOpen)
+
+	_, err = f.PR.Reassign(f.ctx, pairPR.ID, pair[1], selectFirst)
+	expectErr(t, "reassign without candidates", err, domain.ErrNoCandidate)
+
+	_, err = f.PR.Reassign(f.ctx, pairPR.ID, pair[0], selectFirst)
+	expectErr(t, "reassign the author without candidates", err, domain.ErrNotFound)
+}
+
+func testList(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2")
+	author, r1, r2 := ids[0], ids[1], ids[2]
+
+	var prIDs []string
+	for _, name := range []string{"pr-1", "pr-2", "pr-3"} {
+		prIDs = append(prIDs, f.createPR(t, name, author, domain.StatusOpen).ID)
+	}
+	if _, err := f.PR.SetMerged(f.ctx, prIDs[1]); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	list := func(filter domain.PRFilter) domain.ListPRResponse {
+		t.Helper()
+
+		if filter.Limit == 0 {
+			filter.Limit = 10
+		}
+
+		resp, err := f.PR.List(f.ctx, filter)
+		if err != nil {
+			t.Fatalf("list %+v: %v", filter, err)
+		}
+
+		return resp
+	}
+	listed := func(resp domain.ListPRResponse) []string {
+		var ids []string
+		for _, pr := range resp.PullRequests {
+			ids = append(ids, pr.ID)
+		}
+		return ids
+	}
+
+	first := list(domain.PRFilter{AuthorID: author, Limit: 2})
+	expectIDs(t, "first page", listed(first), []string{prIDs[2], prIDs[1]})
+	if first.NextCursor == "" {
+		t.Fatal("first page: no next cursor")
+	}
+	expectIDs(t, "reviewers", first.PullRequests[0].Reviewers, []string{r1, r2})
+
+	second := list(domain.PRFilter{AuthorID: author, Limit: 2, Cursor: first.NextCursor})
+	expectIDs(t, "second page", listed(second), []string{prIDs[0]})
+	if second.NextCursor != "" {
+		t.Fatalf("second page: unexpected cursor %q", second.NextCursor)
+	}
+
+	expectIDs(t, "merged", listed(list(domain.PRFilter{AuthorID: author, Status: domain.StatusMerged})), []string{prIDs[1]})
+	expectIDs(t, "by reviewer", listed(list(domain.PRFilter{ReviewerID: r1})), []string{prIDs[2], prIDs[1], prIDs[0]})
+	expectIDs(t, "by team", listed(list(domain.PRFilter{TeamName: f.id("team")})), []string{prIDs[2], prIDs[1], prIDs[0]})
+
+	merged := list(domain.PRFilter{AuthorID: author, Status: domain.StatusMerged}).PullRequests[0]
+	expectIDs(t, "merged in window", listed(list(domain.PRFilter{AuthorID: author, MergedFrom: merged.MergedAt})), []string{prIDs[1]})
+	expectIDs(t, "merged before window", listed(list(domain.PRFilter{AuthorID: author, MergedTo: merged.MergedAt})), nil)
+
+	_, err := f.PR.List(f.ctx, domain.PRFilter{Limit: 10, Cursor: "%%%"})
+	expectErr(t, "list with bad cursor", err, domain.ErrBadRequest)
+}
+
+func testSetActiveReleasesReviews(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2", "r3")
+	author, r1, r2, r3 := ids[0], ids[1], ids[2], ids[3]
+
+	pr := f.createPR(t, "pr", author, domain.StatusOpen)
+	expectIDs(t, "reviewers", pr.Reviewers, []string{r1, r2})
+
+	resp, err := f.User.SetActive(f.ctx, r1, false, selectFirst)
+	if err != nil {
+		t.Fatalf("deactivate r1: %v", err)
+	}
+	if resp.User.UserID != r1 || resp.User.TeamName != f.id("team") || resp.User.IsActive {
+		t.Fatalf("deactivate r1: got user %+v", resp.User)
+	}
+	want := []domain.Reassignment{{PrID: pr.ID, OldReviewerID: r1, NewReviewerID: r3}}
+	if !slices.Equal(resp.Reassignments, want) {
+		t.Fatalf("deactivate r1: got reassignments %+v, want %+v", resp.Reassignments, want)
+	}
+
+	resp, err = f.User.SetActive(f.ctx, r2, false, selectFirst)
+	if err != nil {
+		t.Fatalf("deactivate r2: %v", err)
+	}
+	want = []domain.Reassignment{{PrID: pr.ID, OldReviewerID: r2}}
+	if !slices.Equal(resp.Reassignments, want) {
+		t.Fatalf("deactivate r2: got reassignments %+v, want %+v", resp.Reassignments, want)
+	}
+
+	got, err := f.PR.Get(f.ctx, pr.ID)
+	if err != nil {
+		t.Fatalf("get pr: %v", err)
+	}
+	expectIDs(t, "reviewers", got.Reviewers, []string{r3})
+
+	events := f.history(t, pr.ID)
+	expectEvent(t, events[len(events)-2], domain.EventReassigned, r1, r3, domain.ReasonUserDeactivated)
+	expectEvent(t, events[len(events)-1], domain.EventUnassigned, r2, "", domain.ReasonUserDeactivated)
+
+	resp, err = f.User.SetActive(f.ctx, r1, true, selectFirst)
+	if err != nil || !resp.User.IsActive || len(resp.Reassignments) != 0 {
+		t.Fatalf("activate r1: got %+v, %v", resp, err)
+	}
+
+	_, err = f.User.SetActive(f.ctx, f.id("nobody"), false, selectFirst)
+	expectErr(t, "deactivate unknown user", err, domain.ErrNotFound)
+}
+
+func testDeactivateTeamMembers(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2", "r3", "r4")
+	author, r1, r2, r3, r4 := ids[0], ids[1], ids[2], ids[3], ids[4]
+
+	pr := f.createPR(t, "pr", author, domain.StatusOpen)
+	expectIDs(t, "reviewers", pr.Reviewers, []string{r1, r2})
+
+	// r3 goes inactive together with r1, so r1's review skips over r3.
+	resp, err := f.User.DeactivateTeamMembers(f.ctx, f.id("team"), []string{r3, r1}, selectFirst)
+	if err != nil {
+		t.Fatalf("deactivate r1, r3: %v", err)
+	}
+	expectIDs(t, "deactivated", resp.DeactivatedUsers, []string{r1, r3})
+	want := []domain.Reassignment{{PrID: pr.ID, OldReviewerID: r1, NewReviewerID: r4}}
+	if !slices.Equal(resp.Reassignments, want) || len(resp.UnderstaffedPRs) != 0 {
+		t.Fatalf("deactivate r1, r3: got %+v", resp)
+	}
+
+	resp, err = f.User.DeactivateTeamMembers(f.ctx, f.id("team"), []string{r2, r4}, selectFirst)
+	if err != nil {
+		t.Fatalf("deactivate r2, r4: %v", err)
+	}
+	expectIDs(t, "understaffed", resp.UnderstaffedPRs, []string{pr.ID})
+	if len(resp.Reassignments) != 2 || resp.Reassignments[0].NewReviewerID != "" || resp.Reassignments[1].NewReviewerID != "" {
+		t.Fatalf("deactivate r2, r4: got reassignments %+v", resp.Reassignments)
+	}
+
+	got, err := f.PR.Get(f.ctx, pr.ID)
+	if err != nil {
+		t.Fatalf("get pr: %v", err)
+	}
+	if len(got.Reviewers) != 0 {
+		t.Fatalf("reviewers: got %v, want none", got.Reviewers)
+	}
+
+	other := f.team(t, "other", "o")[0]
+	_, err = f.User.DeactivateTeamMembers(f.ctx, f.id("team"), []string{author, other}, selectFirst)
+	expectErr(t, "deactivate a non-member", err, domain.ErrNotFound)
+
+	team, err := f.Team.GetTeam(f.ctx, f.id("team"))
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	if !team.Members[0].IsActive {
+		t.Fatal("rejected batch deactivated the author")
+	}
+
+	_, err = f.User.DeactivateTeamMembers(f.ctx, f.id("missing"), []string{author}, selectFirst)
+	expectErr(t, "deactivate in missing team", err, domain.ErrNotFound)
+}
+
+func testGetReview(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2", "r3")
+	author, r1, r3 := ids[0], ids[1], ids[3]
+
+	first := f.createPR(t, "pr-1", author, domain.StatusOpen)
+	second := f.createPR(t, "pr-2", author, domain.StatusOpen)
+	if _, err := f.PR.SetMerged(f.ctx, first.ID); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	resp, err := f.User.GetReview(f.ctx, r1)
+	if err != nil {
+		t.Fatalf("get review: %v", err)
+	}
+	want := []domain.CutPullRequest{
+		{ID: first.ID, Name: "pr-1", AuthorID: author, Status: domain.StatusMerged},
+		{ID: second.ID, Name: "pr-2", AuthorID: author, Status: domain.StatusOpen},
+	}
+	if resp.UserID != r1 || !slices.Equal(resp.PullRequests, want) {
+		t.Fatalf("get review: got %+v, want %+v", resp.PullRequests, want)
+	}
+
+	_, err = f.User.GetReview(f.ctx, r3)
+	expectErr(t, "get review of idle user", err, domain.ErrNotFound)
+}
+
+func memberIDs(team domain.TeamRequest) []string {
+	ids := []string{}
+	for _, member := range team.Members {
+		ids = append(ids, member.ID)
+	}
+
+	return ids
+}
+
+func testAddMembers(t *testing.T, f *fixture) {
+	author := f.team(t, "team", "a")[0]
+	newcomer := f.id("b")
+
+	team, err := f.Team.AddMembers(f.ctx, f.id("team"), []domain.User{
+		{ID: newcomer, Name: "newcomer", IsActive: true},
+		{ID: author, Name: "renamed", IsActive: true},
+	})
+	if err != nil {
+		t.Fatalf("add members: %v", err)
+	}
+	expectIDs(t, "members", memberIDs(team), []string{author, newcomer})
+	if team.TeamName != f.id("team") || team.Members[0].Name != "renamed" || team.Members[1].Name != "newcomer" {
+		t.Fatalf("add members: got team %+v", team)
+	}
+
+	pr := f.createPR(t, "pr", author, domain.StatusOpen)
+	expectIDs(t, "reviewers", pr.Reviewers, []string{newcomer})
+
+	_, err = f.Team.AddMembers(f.ctx, f.id("missing"), []domain.User{{ID: newcomer, Name: "newcomer"}})
+	expectErr(t, "add to missing team", err, domain.ErrNotFound)
+}
+
+func testRemoveMembers(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2", "r3")
+	author, r1, r2, r3 := ids[0], ids[1], ids[2], ids[3]
+	outsider := f.team(t, "other", "o")[0]
+
+	// r1 also reviews for the other team, and keeps that review.
+	if _, err := f.Team.AddMembers(f.ctx, f.id("other"), []domain.User{{ID: r1, Name: r1, IsActive: true}}); err != nil {
+		t.Fatalf("add r1 to other team: %v", err)
+	}
+	foreign := f.createPR(t, "foreign", outsider, domain.StatusOpen)
+	expectIDs(t, "foreign reviewers", foreign.Reviewers, []string{r1})
+
+	pr := f.createPR(t, "pr", author, domain.StatusOpen)
+	expectIDs(t, "reviewers", pr.Reviewers, []string{r1, r2})
+
+	team, reassignments, err := f.Team.RemoveMembers(f.ctx, f.id("team"), []string{r1}, selectFirst)
+	if err != nil {
+		t.Fatalf("remove r1: %v", err)
+	}
+	expectIDs(t, "members", memberIDs(team), []string{author, r2, r3})
+	want := []domain.Reassignment{{PrID: pr.ID, OldReviewerID: r1, NewReviewerID: r3}}
+	if !slices.Equal(reassignments, want) {
+		t.Fatalf("remove r1: got reassignments %+v, want %+v", reassignments, want)
+	}
+
+	events := f.history(t, pr.ID)
+	expectEvent(t, events[len(events)-1], domain.EventReassigned, r1, r3, domain.ReasonMemberRemoved)
+
+	got, err := f.PR.Get(f.ctx, foreign.ID)
+	if err != nil {
+		t.Fatalf("get foreign pr: %v", err)
+	}
+	expectIDs(t, "foreign reviewers", got.Reviewers, []string{r1})
+
+	// Only the author stays, so nobody can take the released reviews.
+	_, reassignments, err = f.Team.RemoveMembers(f.ctx, f.id("team"), []string{r3, r2}, selectFirst)
+	if err != nil {
+		t.Fatalf("remove r2, r3: %v", err)
+	}
+	want = []domain.Reassignment{{PrID: pr.ID, OldReviewerID: r2}, {PrID: pr.ID, OldReviewerID: r3}}
+	if !slices.Equal(reassignments, want) {
+		t.Fatalf("remove r2, r3: got reassignments %+v, want %+v", reassignments, want)
+	}
+
+	got, err = f.PR.Get(f.ctx, pr.ID)
+	if err != nil {
+		t.Fatalf("get pr: %v", err)
+	}
+	if len(got.Reviewers) != 0 {
+		t.Fatalf("reviewers: got %v, want none", got.Reviewers)
+	}
+
+	_, _, err = f.Team.RemoveMembers(f.ctx, f.id("team"), []string{author}, selectFirst)
+	expectErr(t, "remove the last member", err, domain.ErrTeamEmpty)
+
+	team, err = f.Team.GetTeam(f.ctx, f.id("team"))
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	expectIDs(t, "members", memberIDs(team), []string{author})
+
+	_, _, err = f.Team.RemoveMembers(f.ctx, f.id("team"), []string{outsider}, selectFirst)
+	expectErr(t, "remove a non-member", err, domain.ErrNotFound)
+
+	_, _, err = f.Team.RemoveMembers(f.ctx, f.id("missing"), []string{author}, selectFirst)
+	expectErr(t, "remove from missing team", err, domain.ErrNotFound)
+}
+
+func testMoveMember(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2", "r3")
+	author, r1, r2, r3 := ids[0], ids[1], ids[2], ids[3]
+	outsider := f.team(t, "other", "o")[0]
+
+	pr := f.createPR(t, "pr", author, domain.StatusOpen)
+	expectIDs(t, "reviewers", pr.Reviewers, []string{r1, r2})
+
+	team, reassignments, err := f.Team.MoveMember(f.ctx, r1, f.id("team"), f.id("other"), selectFirst)
+	if err != nil {
+		t.Fatalf("move r1: %v", err)
+	}
+	if team.TeamName != f.id("other") {
+		t.Fatalf("move r1: got team %s, want %s", team.TeamName, f.id("other"))
+	}
+	expectIDs(t, "destination members", memberIDs(team), []string{outsider, r1})
+	want := []domain.Reassignment{{PrID: pr.ID, OldReviewerID: r1, NewReviewerID: r3}}
+	if !slices.Equal(reassignments, want) {
+		t.Fatalf("move r1: got reassignments %+v, want %+v", reassignments, want)
+	}
+
+	events := f.history(t, pr.ID)
+	expectEvent(t, events[len(events)-1], domain.EventReassigned, r1, r3, domain.ReasonMemberMoved)
+
+	team, err = f.Team.GetTeam(f.ctx, f.id("team"))
+	if err != nil {
+		t.Fatalf("get team: %v", err)
+	}
+	expectIDs(t, "source members", memberIDs(team), []string{author, r2, r3})
+
+	foreign := f.createPR(t, "foreign", outsider, domain.StatusOpen)
+	expectIDs(t, "foreign reviewers", foreign.Reviewers, []string{r1})
+
+	_, _, err = f.Team.MoveMember(f.ctx, r1, f.id("team"), f.id("other"), selectFirst)
+	expectErr(t, "move a non-member", err, domain.ErrNotFound)
+
+	_, _, err = f.Team.MoveMember(f.ctx, author, f.id("team"), f.id("missing"), selectFirst)
+	expectErr(t, "move to missing team", err, domain.ErrNotFound)
+
+	solo := f.team(t, "solo", "s")[0]
+	_, _, err = f.Team.MoveMember(f.ctx, solo, f.id("solo"), f.id("team"), selectFirst)
+	expectErr(t, "move the last member", err, domain.ErrTeamEmpty)
+}
+
+// testMultipleTeams covers an author in two teams: each PR names its team, and
+// reviewers come from that team only.
+func testMultipleTeams(t *testing.T, f *fixture) {
+	ids := f.team(t, "alpha", "a", "r1", "r2")
+	author, r1, r2 := ids[0], ids[1], ids[2]
+	beta := f.team(t, "beta", "b1", "b2", "b3")
+	b1, b2, b3 := beta[0], beta[1], beta[2]
+	f.team(t, "gamma", "g")
+
+	_, err := f.Team.AddMembers(f.ctx, f.id("beta"), []domain.User{{ID: author, Name: author, IsActive: true}})
+	if err != nil {
+		t.Fatalf("add author to beta: %v", err)
+	}
+
+	_, err = f.PR.Create(f.ctx, f.id("pr"), "pr", author, "", domain.StatusOpen, selectFirst)
+	expectErr(t, "create pr without a team", err, domain.ErrAmbiguousTeam)
+
+	_, err = f.PR.Create(f.ctx, f.id("pr"), "pr", author, f.id("gamma"), domain.StatusOpen, selectFirst)
+	expectErr(t, "create pr in a foreign team", err, domain.ErrNotFound)
+
+	resp, err := f.PR.Create(f.ctx, f.id("pr-alpha"), "alpha", author, f.id("alpha"), domain.StatusOpen, selectFirst)
+	if err != nil {
+		t.Fatalf("create alpha pr: %v", err)
+	}
+	alpha := resp.PR
+	expectIDs(t, "alpha reviewers", alpha.Reviewers, []string{r1, r2})
+
+	resp, err = f.PR.Create(f.ctx, f.id("pr-beta"), "beta", author, f.id("beta"), domain.StatusOpen, selectFirst)
+	if err != nil {
+		t.Fatalf("create beta pr: %v", err)
+	}
+	expectIDs(t, "beta reviewers", resp.PR.Reviewers, []string{b1, b2})
+	if resp.PR.TeamName != f.id("beta") {
+		t.Fatalf("create beta pr: got team %q, want %q", resp.PR.TeamName, f.id("beta"))
+	}
+
+	got, err := f.PR.Get(f.ctx, alpha.ID)
+	if err != nil || got.TeamName != f.id("alpha") {
+		t.Fatalf("get alpha pr: got team %q, %v", got.TeamName, err)
+	}
+
+	list, err := f.PR.List(f.ctx, domain.PRFilter{TeamName: f.id("beta"), Limit: 10})
+	if err != nil {
+		t.Fatalf("list beta prs: %v", err)
+	}
+	if len(list.PullRequests) != 1 || list.PullRequests[0].ID != resp.PR.ID || list.PullRequests[0].TeamName != f.id("beta") {
+		t.Fatalf("list beta prs: got %+v", list.PullRequests)
+	}
+
+	reassigned, err := f.PR.Reassign(f.ctx, resp.PR.ID, b1, selectFirst)
+	if err != nil {
+		t.Fatalf("reassign b1: %v", err)
+	}
+	if reassigned.ReplacedBy != b3 {
+		t.Fatalf("reassign b1: got %s, want %s", reassigned.ReplacedBy, b3)
+	}
+
+	user, err := f.User.SetActive(f.ctx, author, true, selectFirst)
+	if err != nil {
+		t.Fatalf("activate author: %v", err)
+	}
+	expectIDs(t, "author teams", user.User.Teams, []string{f.id("alpha"), f.id("beta")})
+	if user.User.TeamName != f.id("alpha") {
+		t.Fatalf("activate author: got team %q, want %q", user.User.TeamName, f.id("alpha"))
+	}
+}
+
+func testAssignmentStats(t *testing.T, f *fixture) {
+	ids := f.team(t, "alpha", "a", "r1", "r2")
+	author, r1, r2 := ids[0], ids[1], ids[2]
+	b := f.team(t, "beta", "b")[0]
+
+	_, err := f.Team.AddMembers(f.ctx, f.id("beta"), []domain.User{{ID: r1, Name: r1, IsActive: true}})
+	if err != nil {
+		t.Fatalf("add r1 to beta: %v", err)
+	}
+
+	alpha := f.createPR(t, "pr-alpha", author, domain.StatusOpen)
+	expectIDs(t, "alpha reviewers", alpha.Reviewers, []string{r1, r2})
+
+	beta := f.createPR(t, "pr-beta", b, domain.StatusOpen)
+	expectIDs(t, "beta reviewers", beta.Reviewers, []string{r1})
+
+	if _, err := f.PR.SetMerged(f.ctx, beta.ID); err != nil {
+		t.Fatalf("merge beta pr: %v", err)
+	}
+
+	stats, err := f.Stats.GetAssignmentStats(f.ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	users := make(map[string]domain.AssignmentStats)
+	for _, user := range stats.Users {
+		users[user.UserID] = user.AssignmentStats
+	}
+
+	teams := make(map[string]domain.AssignmentStats)
+	for _, team := range stats.Teams {
+		teams[team.TeamName] = team.AssignmentStats
+	}
+
+	// r1 is in both teams, but each assignment counts for the PR's team only.
+	for name, check := range map[string]struct {
+		got, want domain.AssignmentStats
+	}{
+		"r1":    {users[r1], domain.AssignmentStats{Assigned: 2, Open: 1, Merged: 1}},
+		"r2":    {users[r2], domain.AssignmentStats{Assigned: 1, Open: 1}},
+		"alpha": {teams[f.id("alpha")], domain.AssignmentStats{Assigned: 2, Open: 2}},
+		"beta":  {teams[f.id("beta")], domain.AssignmentStats{Assigned: 1, Merged: 1}},
+	} {
+		if check.got != check.want {
+			t.Errorf("%s stats: got %+v, want %+v", name, check.got, check.want)
+		}
+	}
+}
+
+func testListTeams(t *testing.T, f *fixture) {
+	inactive := f.team(t, "alpha", "a1", "a2", "a3")[2]
+	f.team(t, "beta", "b")
+
+	if _, err := f.User.SetActive(f.ctx, inactive, false, selectFirst); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+
+	teams, err := f.Team.ListTeams(f.ctx)
+	if err != nil {
+		t.Fatalf("list teams: %v", err)
+	}
+
+	// Other tests may share the database, so only this test's teams count.
+	var got []domain.TeamSummary
+	for _, team := range teams {
+		if strings.HasSuffix(team.TeamName, f.suffix) {
+			got = append(got, team)
+		}
+	}
+
+	want := []domain.TeamSummary{
+		{TeamName: f.id("alpha"), MembersCount: 3, ActiveMembers: 2},
+		{TeamName: f.id("beta"), MembersCount: 1, ActiveMembers: 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("list teams: got %+v, want %+v", got, want)
+	}
+}
+
+func testRenameTeam(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2")
+	f.team(t, "other", "o")
+
+	pr := f.createPR(t, "pr", ids[0], domain.StatusOpen)
+
+	team, err := f.Team.RenameTeam(f.ctx, f.id("team"), f.id("renamed"))
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if team.TeamName != f.id("renamed") {
+		t.Fatalf("rename: got team %s, want %s", team.TeamName, f.id("renamed"))
+	}
+	expectIDs(t, "members", memberIDs(team), ids)
+
+	_, err = f.Team.GetTeam(f.ctx, f.id("team"))
+	expectErr(t, "get old name", err, domain.ErrNotFound)
+
+	got, err := f.PR.Get(f.ctx, pr.ID)
+	if err != nil || got.TeamName != f.id("renamed") {
+		t.Fatalf("get pr: got team %q, %v", got.TeamName, err)
+	}
+
+	_, err = f.Team.RenameTeam(f.ctx, f.id("renamed"), f.id("other"))
+	expectErr(t, "rename to a taken name", err, domain.ErrTeamExists)
+
+	_, err = f.Team.RenameTeam(f.ctx, f.id("missing"), f.id("any"))
+	expectErr(t, "rename missing team", err, domain.ErrNotFound)
+}
+
+func testDeleteTeam(t *testing.T, f *fixture) {
+	ids := f.team(t, "team", "a", "r1", "r2")
+	author, r1, r2 := ids[0], ids[1], ids[2]
+	other := f.team(t, "other", "o1", "o2")
+
+	_, err := f.Team.AddMembers(f.ctx, f.id("other"), []domain.User{{ID: r1, Name: r1, IsActive: true}})
+	if err != nil {
+		t.Fatalf("add r1 to other: %v", err)
+	}
+
+	pr := f.createPR(t, "pr", author, domain.StatusOpen)
+	expectIDs(t, "reviewers", pr.Reviewers, []string{r1, r2})
+	draft := f.createPR(t, "draft", author, domain.StatusDraft)
+
+	_, err = f.Team.DeleteTeam(f.ctx, f.id("team"), false, "", selectFirst)
+	expectErr(t, "delete team with open prs", err, domain.ErrTeamHasOpenPRs)
+
+	_, err = f.Team.DeleteTeam(f.ctx, f.id("team"), true, f.id("missing"), selectFirst)
+	expectErr(t, "reassign to missing team", err, domain.ErrNotFound)
+
+	resp, err := f.Team.DeleteTeam(f.ctx, f.id("team"), true, f.id("other"), selectFirst)
+	if err != nil {
+		t.Fatalf("delete with reassign: %v", err)
+	}
+	expectIDs(t, "reassigned prs", resp.ReassignedPRs, sorted([]string{pr.ID, draft.ID}))
+	expectIDs(t, "closed prs", resp.ClosedPRs, []string{})
+	want := []domain.Reassignment{{PrID: pr.ID, OldReviewerID: r2, NewReviewerID: other[0]}}
+	if !slices.Equal(resp.Reassignments, want) {
+		t.Fatalf("delete with reassign: got reassignments %+v, want %+v", resp.Reassignments, want)
+	}
+
+	got, err := f.PR.Get(f.ctx, pr.ID)
+	if err != nil || got.TeamName != f.id("other") {
+		t.Fatalf("get moved pr: got team %q, %v", got.TeamName, err)
+	}
+	expectIDs(t, "moved pr reviewers", sorted(got.Reviewers), sorted([]string{r1, other[0]}))
+
+	events := f.history(t, pr.ID)
+	expectEvent(t, events[len(events)-1], domain.EventReassigned, r2, other[0], domain.ReasonTeamDeleted)
+
+	got, err = f.PR.Get(f.ctx, draft.ID)
+	if err != nil || got.TeamName != f.id("other") || got.Status != domain.StatusDraft {
+		t.Fatalf("get moved draft: got %+v, %v", got, err)
+	}
+
+	_, err = f.Team.GetTeam(f.ctx, f.id("team"))
+	expectErr(t, "get deleted team", err, domain.ErrNotFound)
+
+	gone := f.team(t, "gone", "g1", "g2")
+	closed := f.createPR(t, "closed", gone[0], domain.StatusOpen)
+	goneDraft := f.createPR(t, "gone-draft", gone[0], domain.StatusDraft)
+	stale := f.createPR(t, "stale", gone[0], domain.StatusOpen)
+	if _, err := f.PR.SetStatus(f.ctx, stale.ID, domain.StatusOpen, domain.StatusClosed, selectFirst); err != nil {
+		t.Fatalf("close stale pr: %v", err)
+	}
+
+	resp, err = f.Team.DeleteTeam(f.ctx, f.id("gone"), true, "", selectFirst)
+	if err != nil {
+		t.Fatalf("delete with close: %v", err)
+	}
+	expectIDs(t, "closed prs", resp.ClosedPRs, sorted([]string{closed.ID, goneDraft.ID}))
+	expectIDs(t, "reassigned prs", resp.ReassignedPRs, []string{})
+
+	for _, id := range []string{closed.ID, goneDraft.ID, stale.ID} {
+		got, err = f.PR.Get(f.ctx, id)
+		if err != nil || got.Status != domain.StatusClosed || got.TeamName != "" {
+			t.Fatalf("get closed pr %s: got %+v, %v", id, got, err)
+		}
+	}
+
+	_, err = f.PR.SetStatus(f.ctx, goneDraft.ID, domain.StatusClosed, domain.StatusOpen, selectFirst)
+	expectErr(t, "reopen pr without team", err, domain.ErrPRHasNoTeam)
+
+	_, err = f.Team.DeleteTeam(f.ctx, f.id("gone"), false, "", selectFirst)
+	expectErr(t, "delete missing team", err, domain.ErrNotFound)
+}
+
+func testCreateSamePRConcurrently(t *testing.T, f *fixture) {
+	author := f.team(t, "team", "a", "r1", "r2", "r3", "r4", "r5")[0]
+	prID := f.id("pr")
+
+	const workers = 20
+
+	var wg sync.WaitGroup
+	responses := make([]domain.CreatePRResponse, workers)
+	errs := make([]error, workers)
+
+	start := make(chan struct{})
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			responses[i], errs[i] = f.PR.Create(f.ctx, prID, "race", author, "", domain.StatusOpen, selectRandom)
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	var winner domain.CreatePRResponse
+	created := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			created++
+			winner = responses[i]
+		case errors.Is(err, domain.ErrPRExists):
+		default:
+			t.Errorf("worker %d: unexpected error: %v", i, err)
+		}
+	}
+
+	if created != 1 {
+		t.Fatalf("got %d successful creates, want exactly 1", created)
+	}
+
+	stored, err := f.PR.Get(f.ctx, prID)
+	if err != nil {
+		t.Fatalf("get pr: %v", err)
+	}
+	expectIDs(t, "stored reviewers", sorted(stored.Reviewers), sorted(winner.PR.Reviewers))
+
+	if events := f.history(t, prID); len(events) != len(winner.PR.Reviewers) {
+		t.Errorf("got %d assignment events, want %d", len(events), len(winner.PR.Reviewers))
+	}
+}
+
+// testReassignAndMergeConcurrently races both reassigns of a PR against its
+// merge. Whatever the order, the PR ends merged with two distinct reviewers.
+func testReassignAndMergeConcurrently(t *testing.T, f *fixture) {
+	author := f.team(t, "team", "a", "r1", "r2", "r3", "r4", "r5")[0]
+
+	pr, err := f.PR.Create(f.ctx, f.id("pr"), "reassign race", author, "", domain.StatusOpen, selectRandom)
+	if err != nil {
+		t.Fatalf("create pr: %v", err)
+	}
+	if len(pr.PR.Reviewers) != 2 {
+		t.Fatalf("got %d reviewers, want 2", len(pr.PR.Reviewers))
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(pr.PR.Reviewers)+1)
+
+	start := make(chan struct{})
+	for i, reviewerID := range pr.PR.Reviewers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, errs[i] = f.PR.Reassign(f.ctx, pr.PR.ID, reviewerID, selectRandom)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		_, errs[len(errs)-1] = f.PR.SetMerged(f.ctx, pr.PR.ID)
+	}()
+	close(start)
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil && !errors.Is(err, domain.ErrPRMerged) && !errors.Is(err, domain.ErrNoCandidate) {
+			t.Errorf("operation %d: unexpected error: %v", i, err)
+		}
+	}
+
+	stored, err := f.PR.Get(f.ctx, pr.PR.ID)
+	if err != nil {
+		t.Fatalf("get pr: %v", err)
+	}
+	if stored.Status != domain.StatusMerged {
+		t.Errorf("got status %s, want %s", stored.Status, domain.StatusMerged)
+	}
+	if len(stored.Reviewers) != 2 || stored.Reviewers[0] == stored.Reviewers[1] {
+		t.Errorf("got reviewers %v, want two distinct reviewers", stored.Reviewers)
+	}
+}
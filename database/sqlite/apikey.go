@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+
+	apikeyrepo "github.com/dafuqqqyunglean/avito_tech/database/apikey"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepo(db *sql.DB) apikeyrepo.Repository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+//go:embed sql/getTeamID.sql
+var getTeamID string
+
+//go:embed sql/createAPIKey.sql
+var createAPIKey string
+
+func (r *apiKeyRepository) Create(ctx context.Context, name, keyHash, role, teamName string) (domain.APIKey, error) {
+	var teamID *int
+	if teamName != "" {
+		teamID = new(int)
+		err := r.db.QueryRowContext(ctx, getTeamID, teamName).Scan(teamID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.APIKey{}, domain.ErrNotFound
+		}
+		if err != nil {
+			return domain.APIKey{}, fmt.Errorf("failed to get team: %w", err)
+		}
+	}
+
+	key := domain.APIKey{
+		Name:      name,
+		Role:      role,
+		TeamName:  teamName,
+		CreatedAt: now(),
+	}
+
+	err := r.db.QueryRowContext(ctx, createAPIKey, name, keyHash, role, teamID, key.CreatedAt).Scan(&key.ID)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	return key, nil
+}
+
+//go:embed sql/getActiveAPIKey.sql
+var getActiveAPIKey string
+
+func (r *apiKeyRepository) GetActive(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, getActiveAPIKey, keyHash)
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	keys, err := scanAPIKeys(rows)
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+	if len(keys) == 0 {
+		return domain.APIKey{}, domain.ErrNotFound
+	}
+
+	return keys[0], nil
+}
+
+//go:embed sql/listAPIKeys.sql
+var listAPIKeys string
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, listAPIKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return scanAPIKeys(rows)
+}
+
+func scanAPIKeys(rows *sql.Rows) ([]domain.APIKey, error) {
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		var (
+			key       domain.APIKey
+			revokedAt sql.NullTime
+		)
+
+		if err := rows.Scan(&key.ID, &key.Name, &key.Role, &key.TeamName, &key.CreatedAt, &revokedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+
+		if revokedAt.Valid {
+			key.RevokedAt = &revokedAt.Time
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return keys, nil
+}
+
+//go:embed sql/revokeAPIKey.sql
+var revokeAPIKey string
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, revokeAPIKey, id, now())
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	healthrepo "github.com/dafuqqqyunglean/avito_tech/database/health"
+)
+
+type healthRepository struct {
+	db *sql.DB
+}
+
+func NewHealthRepo(db *sql.DB) healthrepo.Repository {
+	return &healthRepository{
+		db: db,
+	}
+}
+
+func (r *healthRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping db: %w", err)
+	}
+
+	return nil
+}
+
+func (r *healthRepository) MigrationVersion(ctx context.Context) (int64, error) {
+	provider, err := newProvider(r.db)
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := provider.GetDBVersion(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return version, nil
+}
@@ -0,0 +1,97 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    username TEXT UNIQUE NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true
+);
+
+CREATE TABLE teams (
+    id INTEGER PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL,
+    assignment_strategy TEXT NOT NULL DEFAULT 'least_loaded'
+        CHECK (assignment_strategy IN ('random', 'round_robin', 'least_loaded')),
+    min_reviewers INTEGER NOT NULL DEFAULT 1 CHECK (min_reviewers >= 0),
+    max_reviewers INTEGER NOT NULL DEFAULT 2 CHECK (max_reviewers >= 1),
+    CONSTRAINT teams_reviewers_range CHECK (min_reviewers <= max_reviewers)
+);
+
+CREATE TABLE team_members (
+    id INTEGER PRIMARY KEY,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (team_id, user_id)
+);
+
+CREATE TABLE pull_requests (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    author_id TEXT REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'OPEN' CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED')),
+    created_at TIMESTAMP NOT NULL,
+    merged_at TIMESTAMP,
+    closed_at TIMESTAMP
+);
+
+CREATE INDEX idx_pull_requests_created_at ON pull_requests (created_at DESC, id DESC);
+
+CREATE INDEX idx_pull_requests_author_id ON pull_requests (author_id);
+
+CREATE TABLE pr_reviewers (
+    id INTEGER PRIMARY KEY,
+    pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id),
+    UNIQUE (pr_id, user_id)
+);
+
+CREATE INDEX idx_pr_reviewers_user_id ON pr_reviewers (user_id);
+
+CREATE TABLE review_assignments (
+    id INTEGER PRIMARY KEY,
+    pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    event TEXT NOT NULL CHECK (event IN ('ASSIGNED', 'REASSIGNED', 'UNASSIGNED', 'MERGED')),
+    old_reviewer_id TEXT,
+    new_reviewer_id TEXT,
+    actor TEXT NOT NULL DEFAULT 'system',
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_review_assignments_pr_id ON review_assignments (pr_id);
+
+CREATE INDEX idx_review_assignments_created_at ON review_assignments (created_at);
+
+CREATE TRIGGER review_assignments_no_update
+    BEFORE UPDATE ON review_assignments
+BEGIN
+    SELECT RAISE(ABORT, 'review_assignments is append-only');
+END;
+
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_hash TEXT UNIQUE NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('admin', 'team-maintainer', 'reader', 'bot')),
+    team_id INTEGER REFERENCES teams(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    CONSTRAINT api_keys_team_check CHECK ((role = 'team-maintainer') = (team_id IS NOT NULL))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys;
+
+DROP TABLE IF EXISTS review_assignments;
+
+DROP TABLE IF EXISTS pr_reviewers;
+
+DROP TABLE IF EXISTS pull_requests;
+
+DROP TABLE IF EXISTS team_members;
+
+DROP TABLE IF EXISTS teams;
+
+DROP TABLE IF EXISTS users;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pull_requests ADD COLUMN team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL;
+
+UPDATE pull_requests
+SET team_id = (
+    SELECT MIN(tm.team_id)
+    FROM team_members tm
+    WHERE tm.user_id = pull_requests.author_id
+);
+
+CREATE INDEX idx_pull_requests_team_id ON pull_requests (team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_pull_requests_team_id;
+
+ALTER TABLE pull_requests DROP COLUMN team_id;
+-- +goose StatementEnd
@@ -0,0 +1,71 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite cannot change a foreign key in place, so the table is rebuilt with
+-- pr_id restricting deletes; dropping the old table drops its trigger too.
+CREATE TABLE review_assignments_new (
+    id INTEGER PRIMARY KEY,
+    pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE RESTRICT,
+    event TEXT NOT NULL CHECK (event IN ('ASSIGNED', 'REASSIGNED', 'UNASSIGNED', 'MERGED')),
+    old_reviewer_id TEXT,
+    new_reviewer_id TEXT,
+    actor TEXT NOT NULL DEFAULT 'system',
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO review_assignments_new
+SELECT id, pr_id, event, old_reviewer_id, new_reviewer_id, actor, reason, created_at
+FROM review_assignments;
+
+DROP TABLE review_assignments;
+
+ALTER TABLE review_assignments_new RENAME TO review_assignments;
+
+CREATE INDEX idx_review_assignments_pr_id ON review_assignments (pr_id);
+
+CREATE INDEX idx_review_assignments_created_at ON review_assignments (created_at);
+
+CREATE TRIGGER review_assignments_no_update
+    BEFORE UPDATE ON review_assignments
+BEGIN
+    SELECT RAISE(ABORT, 'review_assignments is append-only');
+END;
+
+CREATE TRIGGER review_assignments_no_delete
+    BEFORE DELETE ON review_assignments
+BEGIN
+    SELECT RAISE(ABORT, 'review_assignments is append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE review_assignments_old (
+    id INTEGER PRIMARY KEY,
+    pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    event TEXT NOT NULL CHECK (event IN ('ASSIGNED', 'REASSIGNED', 'UNASSIGNED', 'MERGED')),
+    old_reviewer_id TEXT,
+    new_reviewer_id TEXT,
+    actor TEXT NOT NULL DEFAULT 'system',
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+INSERT INTO review_assignments_old
+SELECT id, pr_id, event, old_reviewer_id, new_reviewer_id, actor, reason, created_at
+FROM review_assignments;
+
+DROP TABLE review_assignments;
+
+ALTER TABLE review_assignments_old RENAME TO review_assignments;
+
+CREATE INDEX idx_review_assignments_pr_id ON review_assignments (pr_id);
+
+CREATE INDEX idx_review_assignments_created_at ON review_assignments (created_at);
+
+CREATE TRIGGER review_assignments_no_update
+    BEFORE UPDATE ON review_assignments
+BEGIN
+    SELECT RAISE(ABORT, 'review_assignments is append-only');
+END;
+-- +goose StatementEnd
@@ -0,0 +1,566 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	prrepo "github.com/dafuqqqyunglean/avito_tech/database/pr"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+type prRepository struct {
+	db *sql.DB
+}
+
+func NewPRRepo(db *sql.DB) prrepo.Repository {
+	return &prRepository{
+		db: db,
+	}
+}
+
+//go:embed sql/getPRTeam.sql
+var getPRTeam string
+
+//go:embed sql/selectReviewersFromTeam.sql
+var selectReviewersFromTeam string
+
+// prPool returns the PR's team as a pool without candidates, and the PR's
+// author. It returns sql.ErrNoRows for a missing PR or one without a team.
+func prPool(ctx context.Context, q rowQuerier, prID string) (domain.ReviewerPool, string, error) {
+	var (
+		pool     domain.ReviewerPool
+		authorID string
+	)
+	err := q.QueryRowContext(ctx, getPRTeam, prID).Scan(&pool.TeamName, &pool.Strategy, &pool.MinReviewers, &pool.MaxReviewers, &authorID)
+	if err != nil {
+		return domain.ReviewerPool{}, "", err
+	}
+
+	return pool, authorID, nil
+}
+
+//go:embed sql/createPullRequest.sql
+var createPullRequest string
+
+//go:embed sql/admitReviewer.sql
+var admitReviewer string
+
+//go:embed sql/insertAssignmentEvent.sql
+var insertAssignmentEvent string
+
+// Create stores the PR and, unless it is a draft, its reviewers in a single
+// transaction. The PR goes to teamName, or to the author's only team when
+// teamName is empty. The transaction holds the database's write lock from its
+// start, so concurrent creates see each other's review load.
+func (r *prRepository) Create(ctx context.Context, prID, prName, authorID, teamName, status string, selectReviewers domain.SelectReviewers) (domain.CreatePRResponse, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.CreatePRResponse{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	teamID, pool, err := authorPool(ctx, tx, authorID, teamName)
+	if err != nil {
+		return domain.CreatePRResponse{}, err
+	}
+
+	resp := domain.CreatePRResponse{
+		PR: domain.PullRequest{
+			ID:        prID,
+			Name:      prName,
+			AuthorID:  authorID,
+			TeamName:  pool.TeamName,
+			Status:    status,
+			Reviewers: []string{},
+			CreatedAt: now(),
+		},
+	}
+
+	var id string
+	err = tx.QueryRowContext(ctx, createPullRequest, prID, prName, authorID, status, resp.PR.CreatedAt, teamID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.CreatePRResponse{}, domain.ErrPRExists
+	}
+	if err != nil {
+		return domain.CreatePRResponse{}, fmt.Errorf("failed to save pr to database: %w", err)
+	}
+
+	if status == domain.StatusOpen {
+		pool.Candidates, err = queryCandidates(ctx, tx, selectReviewersFromTeam, authorID, pool.TeamName)
+		if err != nil {
+			return domain.CreatePRResponse{}, fmt.Errorf("failed to get reviewers: %w", err)
+		}
+
+		resp.PR.Reviewers = selectReviewers(pool, pool.MaxReviewers)
+		if len(resp.PR.Reviewers) < pool.MinReviewers {
+			return domain.CreatePRResponse{}, domain.ErrNotEnoughReviewers
+		}
+	}
+
+	for _, reviewerID := range resp.PR.Reviewers {
+		if _, err := tx.ExecContext(ctx, admitReviewer, prID, reviewerID); err != nil {
+			return domain.CreatePRResponse{}, constraintError(err, "failed to assign reviewer "+reviewerID)
+		}
+
+		_, err = tx.ExecContext(ctx, insertAssignmentEvent, prID, domain.EventAssigned, nil, reviewerID,
+			domain.ActorFromContext(ctx), domain.ReasonPRCreated, resp.PR.CreatedAt)
+		if err != nil {
+			return domain.CreatePRResponse{}, fmt.Errorf("failed to record assignment of %s: %w", reviewerID, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.CreatePRResponse{}, fmt.Errorf("transaction uncommitted: %w", err)
+	}
+
+	return resp, nil
+}
+
+//go:embed sql/getAuthorTeams.sql
+var getAuthorTeams string
+
+// authorPool returns the author's team the PR is created in. An empty teamName
+// matches every team of the author, which is ambiguous for authors in more
+// than one.
+func authorPool(ctx context.Context, tx *sql.Tx, authorID, teamName string) (int, domain.ReviewerPool, error) {
+	rows, err := tx.QueryContext(ctx, getAuthorTeams, authorID, nullable(teamName))
+	if err != nil {
+		return 0, domain.ReviewerPool{}, fmt.Errorf("failed to get author team: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		teamID int
+		pool   domain.ReviewerPool
+		found  int
+	)
+	for rows.Next() {
+		if err := rows.Scan(&teamID, &pool.TeamName, &pool.Strategy, &pool.MinReviewers, &pool.MaxReviewers); err != nil {
+			return 0, domain.ReviewerPool{}, fmt.Errorf("scan author team: %w", err)
+		}
+
+		found++
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, domain.ReviewerPool{}, fmt.Errorf("failed to get author team: %w", err)
+	}
+
+	switch found {
+	case 0:
+		return 0, domain.ReviewerPool{}, domain.ErrNotFound
+	case 1:
+		return teamID, pool, nil
+	default:
+		return 0, domain.ReviewerPool{}, domain.ErrAmbiguousTeam
+	}
+}
+
+//go:embed sql/getPRStatus.sql
+var getPRStatus string
+
+//go:embed sql/getPR.sql
+var getPR string
+
+func (r *prRepository) Get(ctx context.Context, prID string) (domain.PullRequest, error) {
+	return loadPR(ctx, r.db, prID)
+}
+
+func (r *prRepository) GetStatus(ctx context.Context, prID string) (string, error) {
+	return prStatus(ctx, r.db, prID)
+}
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func prStatus(ctx context.Context, q rowQuerier, prID string) (string, error) {
+	var status string
+	err := q.QueryRowContext(ctx, getPRStatus, prID).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", domain.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get pr status %s: %w", prID, err)
+	}
+
+	return status, nil
+}
+
+func loadPR(ctx context.Context, q querier, prID string) (domain.PullRequest, error) {
+	rows, err := q.QueryContext(ctx, getPR, prID)
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("failed to load pr %s: %w", prID, err)
+	}
+	defer rows.Close()
+
+	pr := domain.PullRequest{
+		ID:        prID,
+		Reviewers: []string{},
+	}
+
+	var found bool
+	for rows.Next() {
+		var (
+			teamName, reviewerID *string
+			mergedAt             sql.NullTime
+		)
+
+		if err := rows.Scan(&pr.Name, &pr.AuthorID, &teamName, &pr.Status, &reviewerID, &mergedAt, &pr.CreatedAt); err != nil {
+			return domain.PullRequest{}, fmt.Errorf("scan pull request error: %w", err)
+		}
+
+		found = true
+
+		if teamName != nil {
+			pr.TeamName = *teamName
+		}
+
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
+		if reviewerID != nil {
+			pr.Reviewers = append(pr.Reviewers, *reviewerID)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if !found {
+		return domain.PullRequest{}, domain.ErrNotFound
+	}
+
+	return pr, nil
+}
+
+//go:embed sql/listPullRequests.sql
+var listPullRequests string
+
+func (r *prRepository) List(ctx context.Context, filter domain.PRFilter) (domain.ListPRResponse, error) {
+	var (
+		cursorCreatedAt *time.Time
+		cursorID        *string
+	)
+
+	if filter.Cursor != "" {
+		createdAt, id, err := prrepo.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return domain.ListPRResponse{}, domain.ErrBadRequest
+		}
+
+		cursorCreatedAt, cursorID = utc(&createdAt), &id
+	}
+
+	rows, err := r.db.QueryContext(ctx, listPullRequests,
+		nullable(filter.AuthorID),
+		nullable(filter.ReviewerID),
+		nullable(filter.TeamName),
+		nullable(filter.Status),
+		utc(filter.CreatedFrom),
+		utc(filter.CreatedTo),
+		utc(filter.MergedFrom),
+		utc(filter.MergedTo),
+		cursorCreatedAt,
+		cursorID,
+		filter.Limit+1)
+	if err != nil {
+		return domain.ListPRResponse{}, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	defer rows.Close()
+
+	resp := domain.ListPRResponse{
+		PullRequests: []domain.PullRequest{},
+	}
+
+	for rows.Next() {
+		var (
+			pr        domain.PullRequest
+			teamName  *string
+			mergedAt  sql.NullTime
+			reviewers string
+		)
+
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &teamName, &pr.Status, &pr.CreatedAt, &mergedAt, &reviewers); err != nil {
+			return domain.ListPRResponse{}, fmt.Errorf("scan pull request error: %w", err)
+		}
+
+		if teamName != nil {
+			pr.TeamName = *teamName
+		}
+		if mergedAt.Valid {
+			pr.MergedAt = &mergedAt.Time
+		}
+		if err := json.Unmarshal([]byte(reviewers), &pr.Reviewers); err != nil {
+			return domain.ListPRResponse{}, fmt.Errorf("decode reviewers of %s: %w", pr.ID, err)
+		}
+
+		resp.PullRequests = append(resp.PullRequests, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.ListPRResponse{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if len(resp.PullRequests) > filter.Limit {
+		resp.PullRequests = resp.PullRequests[:filter.Limit]
+
+		last := resp.PullRequests[len(resp.PullRequests)-1]
+		resp.NextCursor = prrepo.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return resp, nil
+}
+
+//go:embed sql/updatePRStatus.sql
+var updatePRStatus string
+
+// SetStatus moves the PR from one status to another. A PR that becomes OPEN
+// without reviewers gets them from its team, picked by selectReviewers as in
+// Create; the transaction holds the write lock, so the pool cannot change
+// before they are stored.
+func (r *prRepository) SetStatus(ctx context.Context, prID, from, to string, selectReviewers domain.SelectReviewers) (domain.PullRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	pr, err := loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+	if pr.Status != from {
+		return domain.PullRequest{}, domain.ErrInvalidTransition
+	}
+
+	reviewers := []string{}
+	if to == domain.StatusOpen && len(pr.Reviewers) == 0 {
+		pool, authorID, err := prPool(ctx, tx, prID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.PullRequest{}, domain.ErrPRHasNoTeam
+		}
+		if err != nil {
+			return domain.PullRequest{}, fmt.Errorf("failed to get pr team: %w", err)
+		}
+
+		pool.Candidates, err = queryCandidates(ctx, tx, selectReviewersFromTeam, authorID, pool.TeamName)
+		if err != nil {
+			return domain.PullRequest{}, fmt.Errorf("failed to get reviewers: %w", err)
+		}
+
+		reviewers = selectReviewers(pool, pool.MaxReviewers)
+		if len(reviewers) < pool.MinReviewers {
+			return domain.PullRequest{}, domain.ErrNotEnoughReviewers
+		}
+	}
+
+	changedAt := now()
+
+	var id string
+	err = tx.QueryRowContext(ctx, updatePRStatus, prID, from, to, changedAt).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.PullRequest{}, domain.ErrInvalidTransition
+	}
+	if err != nil {
+		return domain.PullRequest{}, fmt.Errorf("failed to set pr status = %s %s: %w", to, prID, err)
+	}
+
+	reason := domain.ReasonPRReopened
+	if from == domain.StatusDraft {
+		reason = domain.ReasonPRReady
+	}
+
+	for _, reviewerID := range reviewers {
+		if _, err := tx.ExecContext(ctx, admitReviewer, prID, reviewerID); err != nil {
+			return domain.PullRequest{}, constraintError(err, "failed to assign reviewer "+reviewerID)
+		}
+
+		_, err = tx.ExecContext(ctx, insertAssignmentEvent, prID, domain.EventAssigned, nil, reviewerID,
+			domain.ActorFromContext(ctx), reason, changedAt)
+		if err != nil {
+			return domain.PullRequest{}, fmt.Errorf("failed to record assignment of %s: %w", reviewerID, err)
+		}
+	}
+
+	pr, err = loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.PullRequest{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.PullRequest{}, fmt.Errorf("transaction uncommitted: %w", err)
+	}
+
+	return pr, nil
+}
+
+//go:embed sql/setMergedStatus.sql
+var setMergedStatus string
+
+func (r *prRepository) SetMerged(ctx context.Context, prID string) (domain.MergePRResponse, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.MergePRResponse{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	status, err := prStatus(ctx, tx, prID)
+	if err != nil {
+		return domain.MergePRResponse{}, err
+	}
+
+	if status != domain.StatusMerged {
+		mergedAt := now()
+
+		result, err := tx.ExecContext(ctx, setMergedStatus, prID, mergedAt)
+		if err != nil {
+			return domain.MergePRResponse{}, fmt.Errorf("failed to set pr status = merged %s: %w", prID, err)
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return domain.MergePRResponse{}, domain.ErrInvalidTransition
+		}
+
+		_, err = tx.ExecContext(ctx, insertAssignmentEvent, prID, domain.EventMerged, nil, nil,
+			domain.ActorFromContext(ctx), domain.ReasonPRMerged, mergedAt)
+		if err != nil {
+			return domain.MergePRResponse{}, fmt.Errorf("failed to record merge of %s: %w", prID, err)
+		}
+	}
+
+	pr, err := loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.MergePRResponse{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.MergePRResponse{}, fmt.Errorf("transaction uncommitted: %w", err)
+	}
+
+	resp := domain.MergePRResponse{PR: pr}
+	if pr.MergedAt != nil {
+		resp.MergedAt = *pr.MergedAt
+	}
+
+	return resp, nil
+}
+
+//go:embed sql/getAssignmentHistory.sql
+var getAssignmentHistory string
+
+func (r *prRepository) GetHistory(ctx context.Context, prID string) (domain.PRHistoryResponse, error) {
+	if _, err := r.GetStatus(ctx, prID); err != nil {
+		return domain.PRHistoryResponse{}, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, getAssignmentHistory, prID)
+	if err != nil {
+		return domain.PRHistoryResponse{}, fmt.Errorf("failed to get assignment history: %w", err)
+	}
+	defer rows.Close()
+
+	events := []domain.AssignmentEvent{}
+	for rows.Next() {
+		var event domain.AssignmentEvent
+		if err := rows.Scan(&event.ID, &event.Event, &event.OldReviewerID, &event.NewReviewerID,
+			&event.Actor, &event.Reason, &event.CreatedAt); err != nil {
+			return domain.PRHistoryResponse{}, fmt.Errorf("failed to scan assignment history: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.PRHistoryResponse{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return domain.PRHistoryResponse{
+		PrID:   prID,
+		Events: events,
+	}, nil
+}
+
+//go:embed sql/selectReplacementCandidates.sql
+var selectReplacementCandidates string
+
+//go:embed sql/reassignReviewer.sql
+var reassignReviewer string
+
+// Reassign replaces oldUserID on the PR with a teammate chosen by
+// selectReviewers, holding the write lock from the status check to the update.
+// Nothing is selected unless oldUserID reviews the PR.
+func (r *prRepository) Reassign(ctx context.Context, prID, oldUserID string, selectReviewers domain.SelectReviewers) (domain.ReassignPRResponse, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ReassignPRResponse{}, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	pr, err := loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.ReassignPRResponse{}, err
+	}
+	if pr.Status == domain.StatusMerged {
+		return domain.ReassignPRResponse{}, domain.ErrPRMerged
+	}
+	if pr.Status != domain.StatusOpen {
+		return domain.ReassignPRResponse{}, domain.ErrInvalidTransition
+	}
+	if !slices.Contains(pr.Reviewers, oldUserID) {
+		return domain.ReassignPRResponse{}, domain.ErrNotFound
+	}
+
+	pool, _, err := prPool(ctx, tx, prID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ReassignPRResponse{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.ReassignPRResponse{}, fmt.Errorf("failed to get pr team: %w", err)
+	}
+
+	pool.Candidates, err = queryCandidates(ctx, tx, selectReplacementCandidates, prID, oldUserID, pool.TeamName)
+	if err != nil {
+		return domain.ReassignPRResponse{}, fmt.Errorf("failed to get reassign candidates: %w", err)
+	}
+
+	replacement := selectReviewers(pool, 1)
+	if len(replacement) == 0 {
+		return domain.ReassignPRResponse{}, domain.ErrNoCandidate
+	}
+
+	var newID string
+	err = tx.QueryRowContext(ctx, reassignReviewer, prID, oldUserID, replacement[0]).Scan(&newID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ReassignPRResponse{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.ReassignPRResponse{}, constraintError(err, "failed to update pr reviewer")
+	}
+
+	_, err = tx.ExecContext(ctx, insertAssignmentEvent, prID, domain.EventReassigned, oldUserID, newID,
+		domain.ActorFromContext(ctx), domain.ReasonManualReassign, now())
+	if err != nil {
+		return domain.ReassignPRResponse{}, fmt.Errorf("failed to record reassignment: %w", err)
+	}
+
+	pr, err = loadPR(ctx, tx, prID)
+	if err != nil {
+		return domain.ReassignPRResponse{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.ReassignPRResponse{}, fmt.Errorf("transaction uncommitted: %w", err)
+	}
+
+	return domain.ReassignPRResponse{
+		PR:         pr,
+		ReplacedBy: newID,
+	}, nil
+}
@@ -0,0 +1,2 @@
+INSERT INTO pr_reviewers (pr_id, user_id)
+VALUES (?1, ?2);
\ No newline at end of file
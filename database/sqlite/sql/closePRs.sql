@@ -0,0 +1,4 @@
+UPDATE pull_requests
+SET status = 'CLOSED',
+    closed_at = ?2
+WHERE id IN (SELECT value FROM json_each(?1));
\ No newline at end of file
@@ -0,0 +1,3 @@
+SELECT COUNT(*)
+FROM team_members
+WHERE team_id = ?1;
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO api_keys (name, key_hash, role, team_id, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING id;
\ No newline at end of file
@@ -0,0 +1,4 @@
+INSERT INTO pull_requests (id, name, author_id, status, created_at, team_id)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+ON CONFLICT (id) DO NOTHING
+RETURNING id;
\ No newline at end of file
@@ -0,0 +1,4 @@
+INSERT INTO teams (name, assignment_strategy)
+VALUES (?1, ?2)
+ON CONFLICT (name) DO NOTHING
+RETURNING id;
\ No newline at end of file
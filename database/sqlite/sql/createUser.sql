@@ -0,0 +1,5 @@
+INSERT INTO users (id, username, is_active)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (id) DO UPDATE SET
+username = excluded.username,
+is_active = excluded.is_active;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE users
+SET is_active = false
+WHERE id IN (SELECT value FROM json_each(?1));
\ No newline at end of file
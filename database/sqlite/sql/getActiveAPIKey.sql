@@ -0,0 +1,5 @@
+SELECT k.id, k.name, k.role, COALESCE(t.name, ''), k.created_at, k.revoked_at
+FROM api_keys k
+LEFT JOIN teams t ON t.id = k.team_id
+WHERE k.key_hash = ?1
+  AND k.revoked_at IS NULL;
\ No newline at end of file
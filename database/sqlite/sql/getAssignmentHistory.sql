@@ -0,0 +1,4 @@
+SELECT id, event, old_reviewer_id, new_reviewer_id, actor, reason, created_at
+FROM review_assignments
+WHERE pr_id = ?1
+ORDER BY created_at, id;
\ No newline at end of file
@@ -0,0 +1,6 @@
+SELECT t.id, t.name, t.assignment_strategy, t.min_reviewers, t.max_reviewers
+FROM teams t
+JOIN team_members tm ON t.id = tm.team_id
+WHERE tm.user_id = ?1
+  AND (?2 IS NULL OR t.name = ?2)
+ORDER BY t.id;
\ No newline at end of file
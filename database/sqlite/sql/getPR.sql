@@ -0,0 +1,6 @@
+SELECT pr.name, pr.author_id, t.name, pr.status, rv.user_id, pr.merged_at, pr.created_at
+FROM pull_requests pr
+LEFT JOIN teams t ON t.id = pr.team_id
+LEFT JOIN pr_reviewers rv ON pr.id = rv.pr_id
+WHERE pr.id = ?1
+ORDER BY rv.id;
\ No newline at end of file
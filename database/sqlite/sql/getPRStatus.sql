@@ -0,0 +1,3 @@
+SELECT status
+FROM pull_requests
+WHERE id = ?1;
\ No newline at end of file
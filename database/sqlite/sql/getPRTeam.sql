@@ -0,0 +1,4 @@
+SELECT t.name, t.assignment_strategy, t.min_reviewers, t.max_reviewers, pr.author_id
+FROM pull_requests pr
+JOIN teams t ON t.id = pr.team_id
+WHERE pr.id = ?1;
\ No newline at end of file
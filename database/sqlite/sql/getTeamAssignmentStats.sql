@@ -0,0 +1,24 @@
+WITH events AS (
+    SELECT pr.team_id, ra.pr_id, pr.status, ra.old_reviewer_id, ra.new_reviewer_id
+    FROM review_assignments ra
+    JOIN pull_requests pr ON pr.id = ra.pr_id
+    WHERE pr.team_id IS NOT NULL
+      AND (?1 IS NULL OR ra.created_at >= ?1)
+      AND (?2 IS NULL OR ra.created_at < ?2)
+),
+held AS (
+    SELECT DISTINCT e.team_id, e.pr_id, e.new_reviewer_id, e.status
+    FROM events e
+    JOIN pr_reviewers rv ON rv.pr_id = e.pr_id AND rv.user_id = e.new_reviewer_id
+)
+SELECT t.name,
+       COUNT(e.new_reviewer_id),
+       (SELECT COUNT(*) FROM held h WHERE h.team_id = t.id AND h.status = 'OPEN'),
+       (SELECT COUNT(*) FROM held h WHERE h.team_id = t.id AND h.status = 'MERGED'),
+       COUNT(e.old_reviewer_id)
+FROM teams t
+JOIN events e ON e.team_id = t.id
+GROUP BY t.id, t.name
+HAVING COUNT(e.new_reviewer_id) > 0
+    OR COUNT(e.old_reviewer_id) > 0
+ORDER BY t.name;
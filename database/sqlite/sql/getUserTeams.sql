@@ -1,4 +1,5 @@
-SELECT t.name 
+SELECT t.name
 FROM teams t
 JOIN team_members tm ON t.id = tm.team_id
-WHERE tm.user_id = $1;
\ No newline at end of file
+WHERE tm.user_id = ?1
+ORDER BY t.name;
\ No newline at end of file
@@ -0,0 +1,2 @@
+INSERT INTO review_assignments (pr_id, event, old_reviewer_id, new_reviewer_id, actor, reason, created_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7);
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT k.id, k.name, k.role, COALESCE(t.name, ''), k.created_at, k.revoked_at
+FROM api_keys k
+LEFT JOIN teams t ON t.id = k.team_id
+ORDER BY k.id;
\ No newline at end of file
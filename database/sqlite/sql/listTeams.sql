@@ -0,0 +1,8 @@
+SELECT t.name,
+       COUNT(u.id) AS members_count,
+       COUNT(u.id) FILTER (WHERE u.is_active) AS active_members_count
+FROM teams t
+LEFT JOIN team_members tm ON t.id = tm.team_id
+LEFT JOIN users u ON tm.user_id = u.id
+GROUP BY t.id, t.name
+ORDER BY t.name;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE pull_requests
+SET team_id = ?2
+WHERE id IN (SELECT value FROM json_each(?1));
\ No newline at end of file
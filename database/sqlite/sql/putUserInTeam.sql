@@ -0,0 +1,3 @@
+INSERT INTO team_members (team_id, user_id)
+VALUES (?1, ?2)
+ON CONFLICT (team_id, user_id) DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,5 @@
+UPDATE pr_reviewers
+SET user_id = ?3
+WHERE pr_id = ?1
+  AND user_id = ?2
+RETURNING user_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+DELETE FROM pr_reviewers
+WHERE pr_id = ?1
+  AND user_id = ?2;
\ No newline at end of file
@@ -0,0 +1,11 @@
+SELECT u.id, COUNT(pr.id) AS open_reviews
+FROM team_members tm
+JOIN teams t ON tm.team_id = t.id
+JOIN users u ON tm.user_id = u.id
+LEFT JOIN pr_reviewers rv ON rv.user_id = u.id
+LEFT JOIN pull_requests pr ON pr.id = rv.pr_id AND pr.status = 'OPEN'
+WHERE u.id != ?1
+  AND t.name = ?2
+  AND u.is_active = true
+GROUP BY u.id
+ORDER BY u.id;
\ No newline at end of file
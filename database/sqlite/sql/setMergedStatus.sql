@@ -0,0 +1,4 @@
+UPDATE pull_requests
+SET status = 'MERGED', merged_at = ?2
+WHERE id = ?1
+  AND status = 'OPEN';
\ No newline at end of file
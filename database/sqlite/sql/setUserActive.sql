@@ -0,0 +1,3 @@
+UPDATE users SET is_active = ?2
+WHERE id = ?1
+RETURNING id, username, is_active;
\ No newline at end of file
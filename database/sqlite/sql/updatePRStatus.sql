@@ -0,0 +1,6 @@
+UPDATE pull_requests
+SET status = ?3,
+    closed_at = CASE WHEN ?3 = 'CLOSED' THEN ?4 ELSE NULL END
+WHERE id = ?1
+  AND status = ?2
+RETURNING id;
\ No newline at end of file
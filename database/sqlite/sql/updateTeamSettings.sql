@@ -0,0 +1,6 @@
+UPDATE teams
+SET assignment_strategy = ?2,
+    min_reviewers = ?3,
+    max_reviewers = ?4
+WHERE name = ?1
+RETURNING name, assignment_strategy, min_reviewers, max_reviewers;
\ No newline at end of file
@@ -0,0 +1,183 @@
+// Package sqlite stores the service's data in an embedded SQLite database, so
+// the service can run as a single binary. It implements the same repository
+// interfaces as the Postgres packages and brings its own migrations.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"time"
+
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/pressly/goose/v3"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Open opens the database file at path, creating it if needed.
+//
+// Transactions take the write lock when they begin, which gives the same
+// guarantees as the row locks the Postgres repositories take: concurrent
+// writers run one after another instead of failing halfway. Timestamps are
+// written as UTC text, which sorts in time order.
+func Open(path string) (*sql.DB, error) {
+	params := url.Values{
+		"_pragma": {
+			"foreign_keys(1)",
+			"journal_mode(WAL)",
+			"busy_timeout(10000)",
+		},
+		"_txlock":      {"immediate"},
+		"_time_format": {"sqlite"},
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	return db, nil
+}
+
+// Migrate brings the schema up to the newest embedded migration.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	provider, err := newProvider(db)
+	if err != nil {
+		return err
+	}
+
+	if _, err := provider.Up(ctx); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return nil
+}
+
+// LatestMigration returns the version of the newest migration compiled into the
+// binary, which Migrate brings the schema to.
+func LatestMigration(db *sql.DB) (int64, error) {
+	provider, err := newProvider(db)
+	if err != nil {
+		return 0, err
+	}
+
+	sources := provider.ListSources()
+	if len(sources) == 0 {
+		return 0, fmt.Errorf("no migrations found")
+	}
+
+	return sources[len(sources)-1].Version, nil
+}
+
+func newProvider(db *sql.DB) (*goose.Provider, error) {
+	fsys, err := fs.Sub(migrations, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, fsys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return provider, nil
+}
+
+// now matches the precision the Postgres repositories keep.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// utc converts an optional time parameter to the stored layout's zone.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	converted := t.UTC()
+	return &converted
+}
+
+func nullable(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func queryCandidates(ctx context.Context, q querier, query string, args ...any) ([]domain.ReviewCandidate, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []domain.ReviewCandidate{}
+	for rows.Next() {
+		var candidate domain.ReviewCandidate
+		if err := rows.Scan(&candidate.UserID, &candidate.OpenReviews); err != nil {
+			return nil, fmt.Errorf("scan team member: %w", err)
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return candidates, nil
+}
+
+func queryIDs(ctx context.Context, q querier, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan id: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return ids, nil
+}
+
+// constraintError maps integrity violations to domain errors the way the
+// Postgres repositories do.
+func constraintError(err error, message string) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return domain.ErrNoCandidate
+		case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+			return domain.ErrNotFound
+		}
+	}
+
+	return fmt.Errorf("%s: %w", message, err)
+}
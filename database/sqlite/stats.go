@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"time"
+
+	statsrepo "github.com/dafuqqqyunglean/avito_tech/database/stats"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+type statsRepository struct {
+	db *sql.DB
+}
+
+func NewStatsRepo(db *sql.DB) statsrepo.Repository {
+	return &statsRepository{
+		db: db,
+	}
+}
+
+//go:embed sql/getAssignmentStats.sql
+var getAssignmentStats string
+
+//go:embed sql/getTeamAssignmentStats.sql
+var getTeamAssignmentStats string
+
+func (r *statsRepository) GetAssignmentStats(ctx context.Context, from, to *time.Time) (domain.AssignmentStatsResponse, error) {
+	// Both queries read the same snapshot, so user and team figures add up.
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	resp := domain.AssignmentStatsResponse{
+		From:  from,
+		To:    to,
+		Users: []domain.UserAssignmentStats{},
+		Teams: []domain.TeamAssignmentStats{},
+	}
+
+	rows, err := tx.QueryContext(ctx, getAssignmentStats, utc(from), utc(to))
+	if err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("failed to query assignment stats: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user domain.UserAssignmentStats
+		if err := rows.Scan(&user.UserID,
+			&user.Username,
+			&user.Assigned,
+			&user.Open,
+			&user.Merged,
+			&user.ReassignedAway); err != nil {
+			return domain.AssignmentStatsResponse{}, fmt.Errorf("scan assignment stats: %w", err)
+		}
+
+		resp.Users = append(resp.Users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	teamRows, err := tx.QueryContext(ctx, getTeamAssignmentStats, utc(from), utc(to))
+	if err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("failed to query team assignment stats: %w", err)
+	}
+	defer teamRows.Close()
+
+	for teamRows.Next() {
+		var team domain.TeamAssignmentStats
+		if err := teamRows.Scan(&team.TeamName,
+			&team.Assigned,
+			&team.Open,
+			&team.Merged,
+			&team.ReassignedAway); err != nil {
+			return domain.AssignmentStatsResponse{}, fmt.Errorf("scan team assignment stats: %w", err)
+		}
+
+		resp.Teams = append(resp.Teams, team)
+	}
+
+	if err := teamRows.Err(); err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,525 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	teamrepo "github.com/dafuqqqyunglean/avito_tech/database/team"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+type teamRepository struct {
+	db *sql.DB
+}
+
+func NewTeamRepo(db *sql.DB) teamrepo.Repository {
+	return &teamRepository{
+		db: db,
+	}
+}
+
+//go:embed sql/createTeam.sql
+var createTeam string
+
+//go:embed sql/createUser.sql
+var createUser string
+
+//go:embed sql/putUserInTeam.sql
+var putUserInTeam string
+
+func (r *teamRepository) CreateTeam(ctx context.Context, teamName, strategy string, members []domain.User) (domain.TeamRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var teamID int
+	err = tx.QueryRowContext(ctx, createTeam, teamName, strategy).Scan(&teamID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.TeamRequest{}, domain.ErrTeamExists
+	}
+	if err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to create team: %w", err)
+	}
+
+	for _, v := range members {
+		if _, err := tx.ExecContext(ctx, createUser, v.ID, v.Name, v.IsActive); err != nil {
+			return domain.TeamRequest{}, fmt.Errorf("failed to create/update user: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, putUserInTeam, teamID, v.ID); err != nil {
+			return domain.TeamRequest{}, fmt.Errorf("failed to add user to team: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return domain.TeamRequest{TeamName: teamName, AssignmentStrategy: strategy, Members: members}, nil
+}
+
+//go:embed sql/getTeamMembers.sql
+var getTeamMembers string
+
+func (r *teamRepository) GetTeam(ctx context.Context, teamName string) (domain.TeamRequest, error) {
+	return loadTeam(ctx, r.db, teamName)
+}
+
+func loadTeam(ctx context.Context, q querier, teamName string) (domain.TeamRequest, error) {
+	rows, err := q.QueryContext(ctx, getTeamMembers, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to query team: %w", err)
+	}
+	defer rows.Close()
+
+	team := domain.TeamRequest{
+		TeamName: teamName,
+		Members:  []domain.User{},
+	}
+
+	for rows.Next() {
+		var user domain.User
+		if err := rows.Scan(&user.ID, &user.Name, &user.IsActive, &team.AssignmentStrategy); err != nil {
+			return domain.TeamRequest{}, fmt.Errorf("scan team member: %w", err)
+		}
+
+		team.Members = append(team.Members, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if len(team.Members) == 0 {
+		return domain.TeamRequest{}, domain.ErrNotFound
+	}
+
+	return team, nil
+}
+
+//go:embed sql/getTeamSettings.sql
+var getTeamSettings string
+
+func (r *teamRepository) GetSettings(ctx context.Context, teamName string) (domain.TeamSettings, error) {
+	return teamSettings(ctx, r.db, teamName)
+}
+
+func teamSettings(ctx context.Context, q rowQuerier, teamName string) (domain.TeamSettings, error) {
+	var settings domain.TeamSettings
+	err := q.QueryRowContext(ctx, getTeamSettings, teamName).Scan(&settings.TeamName,
+		&settings.AssignmentStrategy,
+		&settings.MinReviewers,
+		&settings.MaxReviewers)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("failed to get team settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+//go:embed sql/updateTeamSettings.sql
+var updateTeamSettings string
+
+func (r *teamRepository) UpdateSettings(ctx context.Context, settings domain.TeamSettings) (domain.TeamSettings, error) {
+	var updated domain.TeamSettings
+	err := r.db.QueryRowContext(ctx, updateTeamSettings,
+		settings.TeamName,
+		settings.AssignmentStrategy,
+		settings.MinReviewers,
+		settings.MaxReviewers).Scan(&updated.TeamName,
+		&updated.AssignmentStrategy,
+		&updated.MinReviewers,
+		&updated.MaxReviewers)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("failed to update team settings: %w", err)
+	}
+
+	return updated, nil
+}
+
+// lookupTeamID returns the id of the team.
+func lookupTeamID(ctx context.Context, q rowQuerier, teamName string) (int, error) {
+	var id int
+	err := q.QueryRowContext(ctx, getTeamID, teamName).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get team %s: %w", teamName, err)
+	}
+
+	return id, nil
+}
+
+// AddMembers creates or updates the users the same way CreateTeam does and
+// puts them in the team. Current members are left as they are.
+func (r *teamRepository) AddMembers(ctx context.Context, teamName string, members []domain.User) (domain.TeamRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	teamID, err := lookupTeamID(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, err
+	}
+
+	for _, v := range members {
+		if _, err := tx.ExecContext(ctx, createUser, v.ID, v.Name, v.IsActive); err != nil {
+			return domain.TeamRequest{}, fmt.Errorf("failed to create/update user: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, putUserInTeam, teamID, v.ID); err != nil {
+			return domain.TeamRequest{}, fmt.Errorf("failed to add user to team: %w", err)
+		}
+	}
+
+	team, err := loadTeam(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return team, nil
+}
+
+// RemoveMembers takes the users out of the team and hands their OPEN reviews
+// on the team's PRs over to the members who stay.
+func (r *teamRepository) RemoveMembers(ctx context.Context, teamName string, userIDs []string, selectReviewers domain.SelectReviewers) (domain.TeamRequest, []domain.Reassignment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	teamID, err := lookupTeamID(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	members, err := leave(ctx, tx, teamID, teamName, userIDs)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	reassignments := []domain.Reassignment{}
+	for _, userID := range members {
+		released, err := releaseReviews(ctx, tx, userID, domain.ReasonMemberRemoved, &teamID, selectReviewers)
+		if err != nil {
+			return domain.TeamRequest{}, nil, err
+		}
+
+		reassignments = append(reassignments, released...)
+	}
+
+	team, err := loadTeam(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return team, reassignments, nil
+}
+
+// MoveMember puts the user in toTeam and takes them out of fromTeam, releasing
+// their reviews on fromTeam's PRs. It returns the destination team.
+func (r *teamRepository) MoveMember(ctx context.Context, userID, fromTeam, toTeam string, selectReviewers domain.SelectReviewers) (domain.TeamRequest, []domain.Reassignment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	fromID, err := lookupTeamID(ctx, tx, fromTeam)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	toID, err := lookupTeamID(ctx, tx, toTeam)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	if _, err := leave(ctx, tx, fromID, fromTeam, []string{userID}); err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, putUserInTeam, toID, userID); err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to add user to team: %w", err)
+	}
+
+	reassignments, err := releaseReviews(ctx, tx, userID, domain.ReasonMemberMoved, &fromID, selectReviewers)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	team, err := loadTeam(ctx, tx, toTeam)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return team, reassignments, nil
+}
+
+//go:embed sql/listTeams.sql
+var listTeams string
+
+func (r *teamRepository) ListTeams(ctx context.Context) ([]domain.TeamSummary, error) {
+	rows, err := r.db.QueryContext(ctx, listTeams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams := []domain.TeamSummary{}
+	for rows.Next() {
+		var team domain.TeamSummary
+		if err := rows.Scan(&team.TeamName, &team.MembersCount, &team.ActiveMembers); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+
+		teams = append(teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return teams, nil
+}
+
+//go:embed sql/renameTeam.sql
+var renameTeam string
+
+func (r *teamRepository) RenameTeam(ctx context.Context, teamName, newName string) (domain.TeamRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	teamID, err := lookupTeamID(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, renameTeam, teamID, newName); err != nil {
+		var sqliteErr *sqlite.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+			return domain.TeamRequest{}, domain.ErrTeamExists
+		}
+
+		return domain.TeamRequest{}, fmt.Errorf("failed to rename team: %w", err)
+	}
+
+	team, err := loadTeam(ctx, tx, newName)
+	if err != nil {
+		return domain.TeamRequest{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return team, nil
+}
+
+//go:embed sql/getTeamPRs.sql
+var getTeamPRs string
+
+//go:embed sql/closePRs.sql
+var closePRs string
+
+//go:embed sql/movePRs.sql
+var movePRs string
+
+//go:embed sql/getForeignReviewers.sql
+var getForeignReviewers string
+
+//go:embed sql/deleteTeam.sql
+var deleteTeam string
+
+// DeleteTeam deletes the team unless it has OPEN PRs. With force, every PR of
+// the team that is not MERGED is moved to reassignTo, and the OPEN ones get
+// their reviewers from outside that team replaced by its members. Without
+// reassignTo those PRs are closed instead and kept without a team, like the
+// MERGED ones.
+func (r *teamRepository) DeleteTeam(ctx context.Context, teamName string, force bool, reassignTo string, selectReviewers domain.SelectReviewers) (domain.DeleteTeamResponse, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.DeleteTeamResponse{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	teamID, err := lookupTeamID(ctx, tx, teamName)
+	if err != nil {
+		return domain.DeleteTeamResponse{}, err
+	}
+
+	var toID int
+	if reassignTo != "" {
+		if toID, err = lookupTeamID(ctx, tx, reassignTo); err != nil {
+			return domain.DeleteTeamResponse{}, err
+		}
+	}
+
+	prIDs, openIDs, unclosedIDs, err := teamPRs(ctx, tx, teamID)
+	if err != nil {
+		return domain.DeleteTeamResponse{}, fmt.Errorf("failed to get team prs: %w", err)
+	}
+	if len(openIDs) > 0 && !force {
+		return domain.DeleteTeamResponse{}, domain.ErrTeamHasOpenPRs
+	}
+
+	resp := domain.DeleteTeamResponse{
+		TeamName:      teamName,
+		ClosedPRs:     []string{},
+		ReassignedPRs: []string{},
+		ReassignedTo:  reassignTo,
+		Reassignments: []domain.Reassignment{},
+	}
+
+	if reassignTo == "" {
+		ids, err := json.Marshal(unclosedIDs)
+		if err != nil {
+			return domain.DeleteTeamResponse{}, fmt.Errorf("failed to encode pr ids: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, closePRs, string(ids), now()); err != nil {
+			return domain.DeleteTeamResponse{}, fmt.Errorf("failed to close prs: %w", err)
+		}
+
+		resp.ClosedPRs = append(resp.ClosedPRs, unclosedIDs...)
+	} else {
+		ids, err := json.Marshal(prIDs)
+		if err != nil {
+			return domain.DeleteTeamResponse{}, fmt.Errorf("failed to encode pr ids: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, movePRs, string(ids), toID); err != nil {
+			return domain.DeleteTeamResponse{}, fmt.Errorf("failed to move prs: %w", err)
+		}
+
+		for _, prID := range openIDs {
+			reviewers, err := queryIDs(ctx, tx, getForeignReviewers, prID, toID)
+			if err != nil {
+				return domain.DeleteTeamResponse{}, fmt.Errorf("failed to get reviewers of %s: %w", prID, err)
+			}
+
+			for _, reviewerID := range reviewers {
+				reassignment, err := releaseReview(ctx, tx, prID, reviewerID, domain.ReasonTeamDeleted, selectReviewers)
+				if err != nil {
+					return domain.DeleteTeamResponse{}, err
+				}
+
+				resp.Reassignments = append(resp.Reassignments, reassignment)
+			}
+		}
+
+		resp.ReassignedPRs = append(resp.ReassignedPRs, prIDs...)
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteTeam, teamID); err != nil {
+		return domain.DeleteTeamResponse{}, fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.DeleteTeamResponse{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return resp, nil
+}
+
+// teamPRs returns the PRs of the team that are not MERGED, along with the OPEN
+// ones among them and the ones not yet CLOSED.
+func teamPRs(ctx context.Context, tx *sql.Tx, teamID int) (prIDs, openIDs, unclosedIDs []string, err error) {
+	rows, err := tx.QueryContext(ctx, getTeamPRs, teamID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+
+	prIDs, openIDs, unclosedIDs = []string{}, []string{}, []string{}
+	for rows.Next() {
+		var id, status string
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, nil, nil, fmt.Errorf("scan pr: %w", err)
+		}
+
+		prIDs = append(prIDs, id)
+		if status == domain.StatusOpen {
+			openIDs = append(openIDs, id)
+		}
+		if status != domain.StatusClosed {
+			unclosedIDs = append(unclosedIDs, id)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return prIDs, openIDs, unclosedIDs, nil
+}
+
+//go:embed sql/removeUsersFromTeam.sql
+var removeUsersFromTeam string
+
+//go:embed sql/countTeamMembers.sql
+var countTeamMembers string
+
+// leave removes the users from the team. Every user must be a member and
+// somebody has to stay.
+func leave(ctx context.Context, tx *sql.Tx, teamID int, teamName string, userIDs []string) ([]string, error) {
+	ids, err := json.Marshal(userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode user ids: %w", err)
+	}
+
+	members, err := queryIDs(ctx, tx, getTeamMemberIDs, teamName, string(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %w", err)
+	}
+	if len(members) != len(userIDs) {
+		return nil, domain.ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, removeUsersFromTeam, teamID, string(ids)); err != nil {
+		return nil, fmt.Errorf("failed to remove users from team: %w", err)
+	}
+
+	var left int
+	if err := tx.QueryRowContext(ctx, countTeamMembers, teamID).Scan(&left); err != nil {
+		return nil, fmt.Errorf("failed to count team members: %w", err)
+	}
+	if left == 0 {
+		return nil, domain.ErrTeamEmpty
+	}
+
+	return members, nil
+}
@@ -0,0 +1,239 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	userrepo "github.com/dafuqqqyunglean/avito_tech/database/user"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+type userRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepo(db *sql.DB) userrepo.Repository {
+	return &userRepository{
+		db: db,
+	}
+}
+
+//go:embed sql/setUserActive.sql
+var setUserActive string
+
+//go:embed sql/getUserTeams.sql
+var getUserTeams string
+
+func (r *userRepository) SetActive(ctx context.Context, userID string, isActive bool, selectReviewers domain.SelectReviewers) (domain.SetActiveResponse, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.SetActiveResponse{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var user domain.SetActiveResponse
+	err = tx.QueryRowContext(ctx, setUserActive, userID, isActive).Scan(&user.User.UserID,
+		&user.User.Username,
+		&user.User.IsActive)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.SetActiveResponse{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.SetActiveResponse{}, fmt.Errorf("failed to set active status: %w", err)
+	}
+
+	user.User.Teams, err = queryIDs(ctx, tx, getUserTeams, userID)
+	if err != nil {
+		return domain.SetActiveResponse{}, fmt.Errorf("failed to get user teams: %w", err)
+	}
+	if len(user.User.Teams) > 0 {
+		user.User.TeamName = user.User.Teams[0]
+	}
+
+	if !isActive {
+		user.Reassignments, err = releaseReviews(ctx, tx, userID, domain.ReasonUserDeactivated, nil, selectReviewers)
+		if err != nil {
+			return domain.SetActiveResponse{}, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.SetActiveResponse{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return user, nil
+}
+
+//go:embed sql/getTeamMemberIDs.sql
+var getTeamMemberIDs string
+
+//go:embed sql/deactivateUsers.sql
+var deactivateUsers string
+
+func (r *userRepository) DeactivateTeamMembers(ctx context.Context, teamName string, userIDs []string, selectReviewers domain.SelectReviewers) (domain.DeactivateUsersResponse, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := lookupTeamID(ctx, tx, teamName); err != nil {
+		return domain.DeactivateUsersResponse{}, err
+	}
+
+	// SQLite has no array parameters; ID lists travel as JSON arrays.
+	ids, err := json.Marshal(userIDs)
+	if err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to encode user ids: %w", err)
+	}
+
+	members, err := queryIDs(ctx, tx, getTeamMemberIDs, teamName, string(ids))
+	if err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to get team members: %w", err)
+	}
+	if len(members) != len(userIDs) {
+		return domain.DeactivateUsersResponse{}, domain.ErrNotFound
+	}
+
+	// Everyone in the batch goes inactive before any review is released, so
+	// the candidate queries never hand a review to another batch member.
+	if _, err = tx.ExecContext(ctx, deactivateUsers, string(ids)); err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to deactivate users: %w", err)
+	}
+
+	resp := domain.DeactivateUsersResponse{
+		TeamName:         teamName,
+		DeactivatedUsers: members,
+		Reassignments:    []domain.Reassignment{},
+		UnderstaffedPRs:  []string{},
+	}
+
+	for _, userID := range members {
+		reassignments, err := releaseReviews(ctx, tx, userID, domain.ReasonTeamDeactivation, nil, selectReviewers)
+		if err != nil {
+			return domain.DeactivateUsersResponse{}, err
+		}
+
+		for _, reassignment := range reassignments {
+			if reassignment.NewReviewerID == "" && !slices.Contains(resp.UnderstaffedPRs, reassignment.PrID) {
+				resp.UnderstaffedPRs = append(resp.UnderstaffedPRs, reassignment.PrID)
+			}
+		}
+
+		resp.Reassignments = append(resp.Reassignments, reassignments...)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return resp, nil
+}
+
+//go:embed sql/getOpenReviews.sql
+var getOpenReviews string
+
+//go:embed sql/removeReviewer.sql
+var removeReviewer string
+
+// releaseReviews hands every OPEN review of the user over to a member of the
+// PR's team picked by that team's strategy. When nobody is left to take a
+// review, the user is dropped from the PR and the reassignment is reported
+// without a new reviewer. With a teamID, only reviews on that team's PRs are
+// released.
+func releaseReviews(ctx context.Context, tx *sql.Tx, userID, reason string, teamID *int, selectReviewers domain.SelectReviewers) ([]domain.Reassignment, error) {
+	prIDs, err := queryIDs(ctx, tx, getOpenReviews, userID, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open reviews: %w", err)
+	}
+
+	reassignments := []domain.Reassignment{}
+	for _, prID := range prIDs {
+		reassignment, err := releaseReview(ctx, tx, prID, userID, reason, selectReviewers)
+		if err != nil {
+			return nil, err
+		}
+
+		reassignments = append(reassignments, reassignment)
+	}
+
+	return reassignments, nil
+}
+
+// releaseReview hands the user's review of the PR over to a member of the PR's
+// team, or drops the user from the PR when nobody is left.
+func releaseReview(ctx context.Context, tx *sql.Tx, prID, userID, reason string, selectReviewers domain.SelectReviewers) (domain.Reassignment, error) {
+	// A PR whose team is gone keeps an empty pool and loses the reviewer.
+	pool, _, err := prPool(ctx, tx, prID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.Reassignment{}, fmt.Errorf("failed to get team of %s: %w", prID, err)
+	}
+
+	pool.Candidates, err = queryCandidates(ctx, tx, selectReplacementCandidates, prID, userID, pool.TeamName)
+	if err != nil {
+		return domain.Reassignment{}, fmt.Errorf("failed to get replacement candidates for %s: %w", prID, err)
+	}
+
+	reassignment := domain.Reassignment{
+		PrID:          prID,
+		OldReviewerID: userID,
+	}
+
+	event, query, args := domain.EventUnassigned, removeReviewer, []any{prID, userID}
+	if picked := selectReviewers(pool, 1); len(picked) > 0 {
+		reassignment.NewReviewerID = picked[0]
+		event, query, args = domain.EventReassigned, reassignReviewer, []any{prID, userID, picked[0]}
+	}
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return domain.Reassignment{}, constraintError(err, "failed to release review of "+prID)
+	}
+
+	_, err = tx.ExecContext(ctx, insertAssignmentEvent, prID, event, userID, nullable(reassignment.NewReviewerID),
+		domain.ActorFromContext(ctx), reason, now())
+	if err != nil {
+		return domain.Reassignment{}, fmt.Errorf("failed to record release of %s: %w", prID, err)
+	}
+
+	return reassignment, nil
+}
+
+//go:embed sql/getUserReviews.sql
+var getUserReviews string
+
+func (r *userRepository) GetReview(ctx context.Context, userID string) (domain.GetReviewResponse, error) {
+	rows, err := r.db.QueryContext(ctx, getUserReviews, userID)
+	if err != nil {
+		return domain.GetReviewResponse{}, fmt.Errorf("failed to get user reviews: %w", err)
+	}
+	defer rows.Close()
+
+	response := domain.GetReviewResponse{
+		UserID:       userID,
+		PullRequests: []domain.CutPullRequest{},
+	}
+
+	for rows.Next() {
+		var pr domain.CutPullRequest
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status); err != nil {
+			return domain.GetReviewResponse{}, fmt.Errorf("failed to scan pull request: %w", err)
+		}
+
+		response.PullRequests = append(response.PullRequests, pr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return domain.GetReviewResponse{}, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if len(response.PullRequests) == 0 {
+		return domain.GetReviewResponse{}, domain.ErrNotFound
+	}
+
+	return response, nil
+}
@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"time"
+
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository interface {
+	GetAssignmentStats(ctx context.Context, from, to *time.Time) (domain.AssignmentStatsResponse, error)
+}
+
+type repository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepo(db *pgxpool.Pool) Repository {
+	return &repository{
+		db: db,
+	}
+}
+
+//go:embed sql/*.sql
+var queries embed.FS
+
+func init() {
+	tracing.MustRegisterQueries(queries)
+}
+
+//go:embed sql/getAssignmentStats.sql
+var getAssignmentStats string
+
+//go:embed sql/getTeamAssignmentStats.sql
+var getTeamAssignmentStats string
+
+// GetAssignmentStats counts the assignments in the window per reviewer and per
+// team. Team figures follow the team each PR belongs to, not the teams its
+// reviewers are in now, so PRs of a deleted team are counted for users only.
+func (r *repository) GetAssignmentStats(ctx context.Context, from, to *time.Time) (domain.AssignmentStatsResponse, error) {
+	// Both queries read the same snapshot, so user and team figures add up.
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, getAssignmentStats, from, to)
+	if err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("failed to query assignment stats: %w", err)
+	}
+
+	users, err := pgx.CollectRows(rows, pgx.RowToStructByPos[domain.UserAssignmentStats])
+	if err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("scan assignment stats: %w", err)
+	}
+
+	rows, err = tx.Query(ctx, getTeamAssignmentStats, from, to)
+	if err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("failed to query team assignment stats: %w", err)
+	}
+
+	teams, err := pgx.CollectRows(rows, pgx.RowToStructByPos[domain.TeamAssignmentStats])
+	if err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("scan team assignment stats: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.AssignmentStatsResponse{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return domain.AssignmentStatsResponse{
+		From:  from,
+		To:    to,
+		Users: users,
+		Teams: teams,
+	}, nil
+}
@@ -0,0 +1,37 @@
+WITH assigned AS (
+    SELECT ra.new_reviewer_id AS user_id, ra.pr_id
+    FROM review_assignments ra
+    WHERE ra.new_reviewer_id IS NOT NULL
+      AND ($1::TIMESTAMP IS NULL OR ra.created_at >= $1)
+      AND ($2::TIMESTAMP IS NULL OR ra.created_at < $2)
+),
+per_user AS (
+    SELECT a.user_id,
+           COUNT(*) AS assigned,
+           COUNT(DISTINCT a.pr_id) FILTER (WHERE pr.status = 'OPEN' AND rv.user_id IS NOT NULL) AS open,
+           COUNT(DISTINCT a.pr_id) FILTER (WHERE pr.status = 'MERGED' AND rv.user_id IS NOT NULL) AS merged
+    FROM assigned a
+    JOIN pull_requests pr ON pr.id = a.pr_id
+    LEFT JOIN pr_reviewers rv ON rv.pr_id = a.pr_id AND rv.user_id = a.user_id
+    GROUP BY a.user_id
+),
+reassigned_away AS (
+    SELECT ra.old_reviewer_id AS user_id, COUNT(*) AS total
+    FROM review_assignments ra
+    WHERE ra.old_reviewer_id IS NOT NULL
+      AND ($1::TIMESTAMP IS NULL OR ra.created_at >= $1)
+      AND ($2::TIMESTAMP IS NULL OR ra.created_at < $2)
+    GROUP BY ra.old_reviewer_id
+)
+SELECT u.id,
+       u.username,
+       COALESCE(p.assigned, 0),
+       COALESCE(p.open, 0),
+       COALESCE(p.merged, 0),
+       COALESCE(r.total, 0)
+FROM users u
+LEFT JOIN per_user p ON p.user_id = u.id
+LEFT JOIN reassigned_away r ON r.user_id = u.id
+WHERE p.user_id IS NOT NULL
+   OR r.user_id IS NOT NULL
+ORDER BY u.id;
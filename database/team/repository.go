@@ -2,18 +2,30 @@ package team
 
 import (
 	"context"
-	_ "embed"
+	"embed"
 	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/dafuqqqyunglean/avito_tech/database/assignment"
 	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Repository interface {
-	CreateTeam(ctx context.Context, teamName string, members []domain.User) (domain.TeamRequest, error)
+	CreateTeam(ctx context.Context, teamName, strategy string, members []domain.User) (domain.TeamRequest, error)
 	GetTeam(ctx context.Context, teamName string) (domain.TeamRequest, error)
+	GetSettings(ctx context.Context, teamName string) (domain.TeamSettings, error)
+	UpdateSettings(ctx context.Context, settings domain.TeamSettings) (domain.TeamSettings, error)
+	AddMembers(ctx context.Context, teamName string, members []domain.User) (domain.TeamRequest, error)
+	RemoveMembers(ctx context.Context, teamName string, userIDs []string, selectReviewers domain.SelectReviewers) (domain.TeamRequest, []domain.Reassignment, error)
+	MoveMember(ctx context.Context, userID, fromTeam, toTeam string, selectReviewers domain.SelectReviewers) (domain.TeamRequest, []domain.Reassignment, error)
+	ListTeams(ctx context.Context) ([]domain.TeamSummary, error)
+	RenameTeam(ctx context.Context, teamName, newName string) (domain.TeamRequest, error)
+	DeleteTeam(ctx context.Context, teamName string, force bool, reassignTo string, selectReviewers domain.SelectReviewers) (domain.DeleteTeamResponse, error)
 }
 
 type repository struct {
@@ -26,6 +38,13 @@ func NewRepo(db *pgxpool.Pool) Repository {
 	}
 }
 
+//go:embed sql/*.sql
+var queries embed.FS
+
+func init() {
+	tracing.MustRegisterQueries(queries)
+}
+
 //go:embed sql/createTeam.sql
 var createTeam string
 
@@ -35,7 +54,7 @@ var createUsers string
 //go:embed sql/putUsersInTeam.sql
 var putUsersInTeam string
 
-func (r *repository) CreateTeam(ctx context.Context, teamName string, members []domain.User) (domain.TeamRequest, error) {
+func (r *repository) CreateTeam(ctx context.Context, teamName, strategy string, members []domain.User) (domain.TeamRequest, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return domain.TeamRequest{}, fmt.Errorf("failed to begin tx: %w", err)
@@ -43,7 +62,7 @@ func (r *repository) CreateTeam(ctx context.Context, teamName string, members []
 	defer tx.Rollback(ctx)
 
 	var teamID int
-	err = tx.QueryRow(ctx, createTeam, teamName).Scan(&teamID)
+	err = tx.QueryRow(ctx, createTeam, teamName, strategy).Scan(&teamID)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return domain.TeamRequest{}, domain.ErrTeamExists
 	}
@@ -71,14 +90,22 @@ func (r *repository) CreateTeam(ctx context.Context, teamName string, members []
 		return domain.TeamRequest{}, fmt.Errorf("failed to commit changes: %w", err)
 	}
 
-	return domain.TeamRequest{TeamName: teamName, Members: members}, nil
+	return domain.TeamRequest{TeamName: teamName, AssignmentStrategy: strategy, Members: members}, nil
 }
 
 //go:embed sql/getTeamMembers.sql
 var getTeamMembers string
 
 func (r *repository) GetTeam(ctx context.Context, teamName string) (domain.TeamRequest, error) {
-	rows, err := r.db.Query(ctx, getTeamMembers, teamName)
+	return loadTeam(ctx, r.db, teamName)
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func loadTeam(ctx context.Context, q querier, teamName string) (domain.TeamRequest, error) {
+	rows, err := q.Query(ctx, getTeamMembers, teamName)
 	if err != nil {
 		return domain.TeamRequest{}, fmt.Errorf("failed to query team: %w", err)
 	}
@@ -94,7 +121,7 @@ func (r *repository) GetTeam(ctx context.Context, teamName string) (domain.TeamR
 		var username string
 		var isActive bool
 
-		if err := rows.Scan(&userID, &username, &isActive); err != nil {
+		if err := rows.Scan(&userID, &username, &isActive, &team.AssignmentStrategy); err != nil {
 			return domain.TeamRequest{}, fmt.Errorf("scan team member: %w", err)
 		}
 
@@ -115,3 +142,428 @@ func (r *repository) GetTeam(ctx context.Context, teamName string) (domain.TeamR
 
 	return team, nil
 }
+
+func (r *repository) GetSettings(ctx context.Context, teamName string) (domain.TeamSettings, error) {
+	return assignment.TeamSettings(ctx, r.db, teamName)
+}
+
+//go:embed sql/updateTeamSettings.sql
+var updateTeamSettings string
+
+func (r *repository) UpdateSettings(ctx context.Context, settings domain.TeamSettings) (domain.TeamSettings, error) {
+	var updated domain.TeamSettings
+	err := r.db.QueryRow(ctx, updateTeamSettings,
+		settings.TeamName,
+		settings.AssignmentStrategy,
+		settings.MinReviewers,
+		settings.MaxReviewers).Scan(&updated.TeamName,
+		&updated.AssignmentStrategy,
+		&updated.MinReviewers,
+		&updated.MaxReviewers)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.TeamSettings{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.TeamSettings{}, fmt.Errorf("failed to update team settings: %w", err)
+	}
+
+	return updated, nil
+}
+
+//go:embed sql/lockTeam.sql
+var lockTeam string
+
+// lockTeamID locks the team row until commit, so membership changes of one
+// team run one at a time, and returns the team's ID.
+func lockTeamID(ctx context.Context, tx pgx.Tx, teamName string) (int, error) {
+	var teamID int
+	err := tx.QueryRow(ctx, lockTeam, teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, domain.ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock team %s: %w", teamName, err)
+	}
+
+	return teamID, nil
+}
+
+// AddMembers creates or updates the users the same way CreateTeam does and
+// puts them in the team. Current members are left as they are.
+func (r *repository) AddMembers(ctx context.Context, teamName string, members []domain.User) (domain.TeamRequest, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	teamID, err := lockTeamID(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, err
+	}
+
+	for _, v := range members {
+		if _, err := tx.Exec(ctx, createUsers, v.ID, v.Name, v.IsActive); err != nil {
+			return domain.TeamRequest{}, fmt.Errorf("failed to create/update user: %w", err)
+		}
+
+		if _, err := tx.Exec(ctx, putUsersInTeam, teamID, v.ID); err != nil {
+			return domain.TeamRequest{}, fmt.Errorf("failed to add user to team: %w", err)
+		}
+	}
+
+	team, err := loadTeam(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return team, nil
+}
+
+//go:embed sql/getTeamMemberIDs.sql
+var getTeamMemberIDs string
+
+//go:embed sql/removeUsersFromTeam.sql
+var removeUsersFromTeam string
+
+//go:embed sql/countTeamMembers.sql
+var countTeamMembers string
+
+// RemoveMembers takes the users out of the team and hands their OPEN reviews
+// on the team's PRs over to the members who stay.
+func (r *repository) RemoveMembers(ctx context.Context, teamName string, userIDs []string, selectReviewers domain.SelectReviewers) (domain.TeamRequest, []domain.Reassignment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	teamID, err := lockTeamID(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	members, err := leave(ctx, tx, teamID, userIDs)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	reassignments := []domain.Reassignment{}
+	for _, userID := range members {
+		released, err := releaseReviews(ctx, tx, teamID, userID, domain.ReasonMemberRemoved, selectReviewers)
+		if err != nil {
+			return domain.TeamRequest{}, nil, err
+		}
+
+		reassignments = append(reassignments, released...)
+	}
+
+	team, err := loadTeam(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return team, reassignments, nil
+}
+
+// MoveMember puts the user in toTeam and takes them out of fromTeam, releasing
+// their reviews on fromTeam's PRs. It returns the destination team.
+func (r *repository) MoveMember(ctx context.Context, userID, fromTeam, toTeam string, selectReviewers domain.SelectReviewers) (domain.TeamRequest, []domain.Reassignment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Both teams are locked in name order, so two opposite moves cannot
+	// deadlock.
+	teamIDs := make(map[string]int, 2)
+	for _, name := range slices.Sorted(slices.Values([]string{fromTeam, toTeam})) {
+		teamID, err := lockTeamID(ctx, tx, name)
+		if err != nil {
+			return domain.TeamRequest{}, nil, err
+		}
+
+		teamIDs[name] = teamID
+	}
+
+	if _, err := leave(ctx, tx, teamIDs[fromTeam], []string{userID}); err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	if _, err := tx.Exec(ctx, putUsersInTeam, teamIDs[toTeam], userID); err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to add user to team: %w", err)
+	}
+
+	reassignments, err := releaseReviews(ctx, tx, teamIDs[fromTeam], userID, domain.ReasonMemberMoved, selectReviewers)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	team, err := loadTeam(ctx, tx, toTeam)
+	if err != nil {
+		return domain.TeamRequest{}, nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.TeamRequest{}, nil, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return team, reassignments, nil
+}
+
+// leave removes the users from a locked team. Every user must be a member and
+// somebody has to stay.
+func leave(ctx context.Context, tx pgx.Tx, teamID int, userIDs []string) ([]string, error) {
+	rows, err := tx.Query(ctx, getTeamMemberIDs, teamID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %w", err)
+	}
+
+	members, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan team members: %w", err)
+	}
+	if len(members) != len(userIDs) {
+		return nil, domain.ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, removeUsersFromTeam, teamID, members); err != nil {
+		return nil, fmt.Errorf("failed to remove users from team: %w", err)
+	}
+
+	var left int
+	if err := tx.QueryRow(ctx, countTeamMembers, teamID).Scan(&left); err != nil {
+		return nil, fmt.Errorf("failed to count team members: %w", err)
+	}
+	if left == 0 {
+		return nil, domain.ErrTeamEmpty
+	}
+
+	return members, nil
+}
+
+//go:embed sql/getTeamOpenReviews.sql
+var getTeamOpenReviews string
+
+// releaseReviews hands the OPEN reviews the user holds on the team's PRs over
+// to a member picked by the team's strategy, or drops the user from the PR when
+// nobody is left. The user must already be out of the team.
+func releaseReviews(ctx context.Context, tx pgx.Tx, teamID int, userID, reason string, selectReviewers domain.SelectReviewers) ([]domain.Reassignment, error) {
+	rows, err := tx.Query(ctx, getTeamOpenReviews, userID, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open reviews: %w", err)
+	}
+
+	prIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan open reviews: %w", err)
+	}
+
+	reassignments := []domain.Reassignment{}
+	for _, prID := range prIDs {
+		reassignment, released, err := assignment.Release(ctx, tx, prID, userID, reason, selectReviewers)
+		if err != nil {
+			return nil, err
+		}
+		if !released {
+			continue
+		}
+
+		reassignments = append(reassignments, reassignment)
+	}
+
+	return reassignments, nil
+}
+
+//go:embed sql/listTeams.sql
+var listTeams string
+
+func (r *repository) ListTeams(ctx context.Context) ([]domain.TeamSummary, error) {
+	rows, err := r.db.Query(ctx, listTeams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	teams, err := pgx.CollectRows(rows, pgx.RowToStructByPos[domain.TeamSummary])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan teams: %w", err)
+	}
+
+	return teams, nil
+}
+
+//go:embed sql/renameTeam.sql
+var renameTeam string
+
+// uniqueViolation is the Postgres error code of a duplicate team name.
+const uniqueViolation = "23505"
+
+func (r *repository) RenameTeam(ctx context.Context, teamName, newName string) (domain.TeamRequest, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	teamID, err := lockTeamID(ctx, tx, teamName)
+	if err != nil {
+		return domain.TeamRequest{}, err
+	}
+
+	if _, err := tx.Exec(ctx, renameTeam, teamID, newName); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return domain.TeamRequest{}, domain.ErrTeamExists
+		}
+
+		return domain.TeamRequest{}, fmt.Errorf("failed to rename team: %w", err)
+	}
+
+	team, err := loadTeam(ctx, tx, newName)
+	if err != nil {
+		return domain.TeamRequest{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.TeamRequest{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return team, nil
+}
+
+//go:embed sql/lockTeamPRs.sql
+var lockTeamPRs string
+
+//go:embed sql/closePRs.sql
+var closePRs string
+
+//go:embed sql/movePRs.sql
+var movePRs string
+
+//go:embed sql/getForeignReviewers.sql
+var getForeignReviewers string
+
+//go:embed sql/deleteTeam.sql
+var deleteTeam string
+
+// DeleteTeam deletes the team unless it has OPEN PRs. With force, every PR of
+// the team that is not MERGED is moved to reassignTo, and the OPEN ones get
+// their reviewers from outside that team replaced by its members. Without
+// reassignTo those PRs are closed instead and kept without a team, like the
+// MERGED ones.
+func (r *repository) DeleteTeam(ctx context.Context, teamName string, force bool, reassignTo string, selectReviewers domain.SelectReviewers) (domain.DeleteTeamResponse, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.DeleteTeamResponse{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Both teams are locked in name order, as in MoveMember.
+	names := []string{teamName}
+	if reassignTo != "" {
+		names = append(names, reassignTo)
+	}
+
+	teamIDs := make(map[string]int, len(names))
+	for _, name := range slices.Sorted(slices.Values(names)) {
+		teamID, err := lockTeamID(ctx, tx, name)
+		if err != nil {
+			return domain.DeleteTeamResponse{}, err
+		}
+
+		teamIDs[name] = teamID
+	}
+
+	rows, err := tx.Query(ctx, lockTeamPRs, teamIDs[teamName])
+	if err != nil {
+		return domain.DeleteTeamResponse{}, fmt.Errorf("failed to get team prs: %w", err)
+	}
+
+	var (
+		id, status string
+
+		prIDs, openIDs, unclosedIDs = []string{}, []string{}, []string{}
+	)
+	_, err = pgx.ForEachRow(rows, []any{&id, &status}, func() error {
+		prIDs = append(prIDs, id)
+		if status == domain.StatusOpen {
+			openIDs = append(openIDs, id)
+		}
+		if status != domain.StatusClosed {
+			unclosedIDs = append(unclosedIDs, id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.DeleteTeamResponse{}, fmt.Errorf("failed to scan team prs: %w", err)
+	}
+	if len(openIDs) > 0 && !force {
+		return domain.DeleteTeamResponse{}, domain.ErrTeamHasOpenPRs
+	}
+
+	resp := domain.DeleteTeamResponse{
+		TeamName:      teamName,
+		ClosedPRs:     []string{},
+		ReassignedPRs: []string{},
+		ReassignedTo:  reassignTo,
+		Reassignments: []domain.Reassignment{},
+	}
+
+	if reassignTo == "" {
+		if _, err := tx.Exec(ctx, closePRs, unclosedIDs); err != nil {
+			return domain.DeleteTeamResponse{}, fmt.Errorf("failed to close prs: %w", err)
+		}
+
+		resp.ClosedPRs = append(resp.ClosedPRs, unclosedIDs...)
+	} else {
+		if _, err := tx.Exec(ctx, movePRs, prIDs, teamIDs[reassignTo]); err != nil {
+			return domain.DeleteTeamResponse{}, fmt.Errorf("failed to move prs: %w", err)
+		}
+
+		for _, prID := range openIDs {
+			rows, err := tx.Query(ctx, getForeignReviewers, prID, teamIDs[reassignTo])
+			if err != nil {
+				return domain.DeleteTeamResponse{}, fmt.Errorf("failed to get reviewers of %s: %w", prID, err)
+			}
+
+			reviewers, err := pgx.CollectRows(rows, pgx.RowTo[string])
+			if err != nil {
+				return domain.DeleteTeamResponse{}, fmt.Errorf("failed to scan reviewers of %s: %w", prID, err)
+			}
+
+			for _, reviewerID := range reviewers {
+				reassignment, released, err := assignment.Release(ctx, tx, prID, reviewerID, domain.ReasonTeamDeleted, selectReviewers)
+				if err != nil {
+					return domain.DeleteTeamResponse{}, err
+				}
+				if !released {
+					continue
+				}
+
+				resp.Reassignments = append(resp.Reassignments, reassignment)
+			}
+		}
+
+		resp.ReassignedPRs = append(resp.ReassignedPRs, prIDs...)
+	}
+
+	if _, err := tx.Exec(ctx, deleteTeam, teamIDs[teamName]); err != nil {
+		return domain.DeleteTeamResponse{}, fmt.Errorf("failed to delete team: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.DeleteTeamResponse{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return resp, nil
+}
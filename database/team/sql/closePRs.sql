@@ -0,0 +1,4 @@
+UPDATE pull_requests
+SET status = 'CLOSED',
+    closed_at = NOW()
+WHERE id = ANY($1);
\ No newline at end of file
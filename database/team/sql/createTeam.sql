@@ -1,4 +1,4 @@
-INSERT INTO teams (name) 
-VALUES ($1) 
+INSERT INTO teams (name, assignment_strategy) 
+VALUES ($1, $2) 
 ON CONFLICT (name) DO NOTHING
-RETURNING id;
\ No newline at end of file
+RETURNING id;
@@ -0,0 +1,9 @@
+SELECT user_id
+FROM pr_reviewers
+WHERE pr_id = $1
+  AND user_id NOT IN (
+      SELECT user_id
+      FROM team_members
+      WHERE team_id = $2
+  )
+ORDER BY user_id;
\ No newline at end of file
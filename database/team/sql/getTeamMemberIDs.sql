@@ -0,0 +1,5 @@
+SELECT user_id
+FROM team_members
+WHERE team_id = $1
+  AND user_id = ANY($2)
+ORDER BY user_id;
\ No newline at end of file
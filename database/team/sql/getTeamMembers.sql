@@ -1,9 +1,10 @@
 SELECT 
     u.id as user_internal_id,
     u.username,
-    u.is_active
+    u.is_active,
+    t.assignment_strategy
 FROM teams t
 JOIN team_members tm ON t.id = tm.team_id
 JOIN users u ON tm.user_id = u.id
 WHERE t.name = $1
-ORDER BY u.id;
\ No newline at end of file
+ORDER BY u.id;
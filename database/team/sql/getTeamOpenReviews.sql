@@ -0,0 +1,7 @@
+SELECT pr.id
+FROM pull_requests pr
+JOIN pr_reviewers rv ON pr.id = rv.pr_id
+WHERE rv.user_id = $1
+  AND pr.status = 'OPEN'
+  AND pr.team_id = $2
+ORDER BY pr.id;
\ No newline at end of file
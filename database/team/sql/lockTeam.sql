@@ -0,0 +1,4 @@
+SELECT id
+FROM teams
+WHERE name = $1
+FOR UPDATE;
\ No newline at end of file
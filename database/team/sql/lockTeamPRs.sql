@@ -0,0 +1,6 @@
+SELECT id, status
+FROM pull_requests
+WHERE team_id = $1
+  AND status <> 'MERGED'
+ORDER BY id
+FOR UPDATE;
\ No newline at end of file
@@ -0,0 +1,3 @@
+UPDATE pull_requests
+SET team_id = $2
+WHERE id = ANY($1);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DELETE FROM team_members
+WHERE team_id = $1
+  AND user_id = ANY($2);
\ No newline at end of file
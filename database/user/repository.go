@@ -2,17 +2,21 @@ package user
 
 import (
 	"context"
-	_ "embed"
+	"embed"
 	"errors"
 	"fmt"
+	"slices"
 
+	"github.com/dafuqqqyunglean/avito_tech/database/assignment"
 	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Repository interface {
-	SetActive(ctx context.Context, userID string, isActive bool) (domain.SetActiveResponse, error)
+	SetActive(ctx context.Context, userID string, isActive bool, selectReviewers domain.SelectReviewers) (domain.SetActiveResponse, error)
+	DeactivateTeamMembers(ctx context.Context, teamName string, userIDs []string, selectReviewers domain.SelectReviewers) (domain.DeactivateUsersResponse, error)
 	GetReview(ctx context.Context, userID string) (domain.GetReviewResponse, error)
 }
 
@@ -26,15 +30,28 @@ func NewRepo(db *pgxpool.Pool) Repository {
 	}
 }
 
+//go:embed sql/*.sql
+var queries embed.FS
+
+func init() {
+	tracing.MustRegisterQueries(queries)
+}
+
 //go:embed sql/setUserActive.sql
 var setUserActive string
 
-//go:embed sql/getTeamName.sql
-var getTeamName string
+//go:embed sql/getUserTeams.sql
+var getUserTeams string
+
+func (r *repository) SetActive(ctx context.Context, userID string, isActive bool, selectReviewers domain.SelectReviewers) (domain.SetActiveResponse, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.SetActiveResponse{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-func (r *repository) SetActive(ctx context.Context, userID string, isActive bool) (domain.SetActiveResponse, error) {
 	var user domain.SetActiveResponse
-	err := r.db.QueryRow(ctx, setUserActive,
+	err = tx.QueryRow(ctx, setUserActive,
 		userID,
 		isActive).Scan(&user.User.UserID,
 		&user.User.Username,
@@ -46,18 +63,161 @@ func (r *repository) SetActive(ctx context.Context, userID string, isActive bool
 		return domain.SetActiveResponse{}, fmt.Errorf("failed to set active status: %w", err)
 	}
 
-	err = r.db.QueryRow(ctx, getTeamName,
-		userID).Scan(&user.User.TeamName)
-	if errors.Is(err, pgx.ErrNoRows) {
-		return domain.SetActiveResponse{}, domain.ErrNotFound
+	rows, err := tx.Query(ctx, getUserTeams, userID)
+	if err != nil {
+		return domain.SetActiveResponse{}, fmt.Errorf("failed to get user teams: %w", err)
 	}
+
+	user.User.Teams, err = pgx.CollectRows(rows, pgx.RowTo[string])
 	if err != nil {
-		return domain.SetActiveResponse{}, fmt.Errorf("failed to get user team: %w", err)
+		return domain.SetActiveResponse{}, fmt.Errorf("failed to scan user teams: %w", err)
+	}
+	if len(user.User.Teams) > 0 {
+		user.User.TeamName = user.User.Teams[0]
+	}
+
+	if !isActive {
+		user.Reassignments, err = r.releaseReviews(ctx, tx, userID, domain.ReasonUserDeactivated, selectReviewers)
+		if err != nil {
+			return domain.SetActiveResponse{}, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.SetActiveResponse{}, fmt.Errorf("failed to commit changes: %w", err)
 	}
 
 	return user, nil
 }
 
+//go:embed sql/getOpenReviews.sql
+var getOpenReviews string
+
+//go:embed sql/getTeamMemberIDs.sql
+var getTeamMemberIDs string
+
+//go:embed sql/deactivateUsers.sql
+var deactivateUsers string
+
+//go:embed sql/lockTeam.sql
+var lockTeam string
+
+//go:embed sql/lockOpenReviews.sql
+var lockOpenReviews string
+
+// DeactivateTeamMembers deactivates the users and releases their OPEN reviews.
+// Users and then PRs are locked in ID order, the order SetActive takes them
+// in, so overlapping batches wait for each other instead of deadlocking.
+func (r *repository) DeactivateTeamMembers(ctx context.Context, teamName string, userIDs []string, selectReviewers domain.SelectReviewers) (domain.DeactivateUsersResponse, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var teamID int
+	err = tx.QueryRow(ctx, lockTeam, teamName).Scan(&teamID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.DeactivateUsersResponse{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to lock team %s: %w", teamName, err)
+	}
+
+	rows, err := tx.Query(ctx, getTeamMemberIDs, teamName, userIDs)
+	if err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to get team members: %w", err)
+	}
+
+	members, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to scan team members: %w", err)
+	}
+	if len(members) != len(userIDs) {
+		return domain.DeactivateUsersResponse{}, domain.ErrNotFound
+	}
+
+	// Everyone in the batch goes inactive before any review is released, so
+	// the candidate queries never hand a review to another batch member.
+	if _, err = tx.Exec(ctx, deactivateUsers, userIDs); err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to deactivate users: %w", err)
+	}
+
+	resp := domain.DeactivateUsersResponse{
+		TeamName:         teamName,
+		DeactivatedUsers: members,
+		Reassignments:    []domain.Reassignment{},
+		UnderstaffedPRs:  []string{},
+	}
+
+	rows, err = tx.Query(ctx, lockOpenReviews, members)
+	if err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to lock open reviews: %w", err)
+	}
+
+	reviews, err := pgx.CollectRows(rows, pgx.RowToStructByPos[openReview])
+	if err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to scan open reviews: %w", err)
+	}
+
+	for _, review := range reviews {
+		reassignment, released, err := assignment.Release(ctx, tx, review.PrID, review.UserID, domain.ReasonTeamDeactivation, selectReviewers)
+		if err != nil {
+			return domain.DeactivateUsersResponse{}, err
+		}
+		if !released {
+			continue
+		}
+
+		if reassignment.NewReviewerID == "" && !slices.Contains(resp.UnderstaffedPRs, reassignment.PrID) {
+			resp.UnderstaffedPRs = append(resp.UnderstaffedPRs, reassignment.PrID)
+		}
+
+		resp.Reassignments = append(resp.Reassignments, reassignment)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return domain.DeactivateUsersResponse{}, fmt.Errorf("failed to commit changes: %w", err)
+	}
+
+	return resp, nil
+}
+
+// openReview is an OPEN PR reviewed by the user.
+type openReview struct {
+	PrID   string
+	UserID string
+}
+
+// releaseReviews hands every OPEN review of the user over to a member of the
+// PR's team, or drops the user from the PR when nobody is left to take it.
+func (r *repository) releaseReviews(ctx context.Context, tx pgx.Tx, userID, reason string, selectReviewers domain.SelectReviewers) ([]domain.Reassignment, error) {
+	rows, err := tx.Query(ctx, getOpenReviews, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open reviews: %w", err)
+	}
+
+	prIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan open reviews: %w", err)
+	}
+
+	reassignments := []domain.Reassignment{}
+	for _, prID := range prIDs {
+		reassignment, released, err := assignment.Release(ctx, tx, prID, userID, reason, selectReviewers)
+		if err != nil {
+			return nil, err
+		}
+		if !released {
+			continue
+		}
+
+		reassignments = append(reassignments, reassignment)
+	}
+
+	return reassignments, nil
+}
+
 //go:embed sql/getPR.sql
 var getPR string
 
@@ -0,0 +1,9 @@
+UPDATE users
+SET is_active = false
+WHERE id IN (
+    SELECT id
+    FROM users
+    WHERE id = ANY($1)
+    ORDER BY id
+    FOR UPDATE
+);
\ No newline at end of file
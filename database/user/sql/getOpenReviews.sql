@@ -0,0 +1,6 @@
+SELECT pr.id
+FROM pull_requests pr
+JOIN pr_reviewers rv ON pr.id = rv.pr_id
+WHERE rv.user_id = $1
+  AND pr.status = 'OPEN'
+ORDER BY pr.id;
@@ -0,0 +1,6 @@
+SELECT tm.user_id
+FROM team_members tm
+JOIN teams t ON t.id = tm.team_id
+WHERE t.name = $1
+  AND tm.user_id = ANY($2)
+ORDER BY tm.user_id;
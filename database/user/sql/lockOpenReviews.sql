@@ -0,0 +1,7 @@
+SELECT pr.id, rv.user_id
+FROM pull_requests pr
+JOIN pr_reviewers rv ON pr.id = rv.pr_id
+WHERE rv.user_id = ANY($1)
+  AND pr.status = 'OPEN'
+ORDER BY pr.id, rv.user_id
+FOR UPDATE OF pr;
\ No newline at end of file
@@ -0,0 +1,4 @@
+SELECT id
+FROM teams
+WHERE name = $1
+FOR SHARE;
\ No newline at end of file
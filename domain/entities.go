@@ -1,5 +1,47 @@
 package domain
 
+import "time"
+
+const (
+	StatusDraft  = "DRAFT"
+	StatusOpen   = "OPEN"
+	StatusMerged = "MERGED"
+	StatusClosed = "CLOSED"
+)
+
+const (
+	EventAssigned   = "ASSIGNED"
+	EventReassigned = "REASSIGNED"
+	EventUnassigned = "UNASSIGNED"
+	EventMerged     = "MERGED"
+)
+
+const (
+	ReasonPRCreated        = "pr_created"
+	ReasonPRReady          = "pr_ready"
+	ReasonPRReopened       = "pr_reopened"
+	ReasonPRMerged         = "pr_merged"
+	ReasonManualReassign   = "manual_reassign"
+	ReasonUserDeactivated  = "user_deactivated"
+	ReasonTeamDeactivation = "team_deactivation"
+	ReasonMemberRemoved    = "member_removed"
+	ReasonMemberMoved      = "member_moved"
+	ReasonTeamDeleted      = "team_deleted"
+)
+
+const (
+	StrategyRandom      = "random"
+	StrategyRoundRobin  = "round_robin"
+	StrategyLeastLoaded = "least_loaded"
+)
+
+const (
+	RoleAdmin          = "admin"
+	RoleTeamMaintainer = "team-maintainer"
+	RoleReader         = "reader"
+	RoleBot            = "bot"
+)
+
 type User struct {
 	ID       string `json:"user_id"`
 	Name     string `json:"username"`
@@ -13,9 +55,108 @@ type Team struct {
 }
 
 type PullRequest struct {
-	ID        string   `json:"pull_request_id"`
-	Name      string   `json:"pull_request_name"`
-	AuthorID  string   `json:"author_id"`
-	Status    string   `json:"status"`
-	Reviewers []string `json:"assigned_reviewers"`
+	ID        string     `json:"pull_request_id"`
+	Name      string     `json:"pull_request_name"`
+	AuthorID  string     `json:"author_id"`
+	TeamName  string     `json:"team_name,omitempty"`
+	Status    string     `json:"status"`
+	Reviewers []string   `json:"assigned_reviewers"`
+	CreatedAt time.Time  `json:"created_at,omitzero"`
+	MergedAt  *time.Time `json:"merged_at,omitempty"`
+}
+
+type PRFilter struct {
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MergedFrom  *time.Time
+	MergedTo    *time.Time
+	Cursor      string
+	Limit       int
+}
+
+type ReviewCandidate struct {
+	UserID      string
+	OpenReviews int
+}
+
+type TeamSettings struct {
+	TeamName           string `json:"team_name"`
+	AssignmentStrategy string `json:"assignment_strategy"`
+	MinReviewers       int    `json:"min_reviewers"`
+	MaxReviewers       int    `json:"max_reviewers"`
+}
+
+type TeamSummary struct {
+	TeamName      string `json:"team_name"`
+	MembersCount  int    `json:"members_count"`
+	ActiveMembers int    `json:"active_members_count"`
+}
+
+type ReviewerPool struct {
+	TeamName     string
+	Strategy     string
+	MinReviewers int
+	MaxReviewers int
+	Candidates   []ReviewCandidate
+}
+
+// SelectReviewers picks up to count reviewers from the pool. Repositories call
+// it while holding a transaction, so selection sees the same data as the write.
+type SelectReviewers func(pool ReviewerPool, count int) []string
+
+type Reassignment struct {
+	PrID          string `json:"pull_request_id"`
+	OldReviewerID string `json:"old_reviewer_id"`
+	NewReviewerID string `json:"new_reviewer_id,omitempty"`
+}
+
+type AssignmentStats struct {
+	Assigned       int `json:"assigned"`
+	Open           int `json:"open"`
+	Merged         int `json:"merged"`
+	ReassignedAway int `json:"reassigned_away"`
+}
+
+type UserAssignmentStats struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	AssignmentStats
+}
+
+type TeamAssignmentStats struct {
+	TeamName string `json:"team_name"`
+	AssignmentStats
+}
+
+type AssignmentEvent struct {
+	ID            int64     `json:"id"`
+	Event         string    `json:"event"`
+	OldReviewerID *string   `json:"old_reviewer_id,omitempty"`
+	NewReviewerID *string   `json:"new_reviewer_id,omitempty"`
+	Actor         string    `json:"actor"`
+	Reason        string    `json:"reason"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// APIKey describes a stored API key. The key itself is never kept, only its
+// SHA-256 hash.
+type APIKey struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	TeamName  string     `json:"team_name,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Principal is the authenticated caller of a request. TeamName is set only for
+// team maintainers.
+type Principal struct {
+	Name     string
+	Role     string
+	TeamName string
 }
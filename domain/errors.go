@@ -30,6 +30,21 @@ var (
 		Message: "team_name already exists",
 	}
 
+	ErrTeamEmpty = Error{
+		Code:    "TEAM_EMPTY",
+		Message: "team must keep at least one member",
+	}
+
+	ErrTeamHasOpenPRs = Error{
+		Code:    "TEAM_HAS_OPEN_PRS",
+		Message: "team has OPEN pull requests, delete it with force",
+	}
+
+	ErrAmbiguousTeam = Error{
+		Code:    "TEAM_AMBIGUOUS",
+		Message: "author belongs to several teams, team_name is required",
+	}
+
 	ErrPRExists = Error{
 		Code:    "PR_EXISTS",
 		Message: "PR id already exists",
@@ -40,6 +55,36 @@ var (
 		Message: "cannot reassign on merged PR",
 	}
 
+	ErrNoCandidate = Error{
+		Code:    "NO_CANDIDATE",
+		Message: "no active replacement candidate in team",
+	}
+
+	ErrInvalidTransition = Error{
+		Code:    "INVALID_STATUS_TRANSITION",
+		Message: "action is not allowed in the current PR status",
+	}
+
+	ErrPRHasNoTeam = Error{
+		Code:    "PR_HAS_NO_TEAM",
+		Message: "PR has no team to assign reviewers from",
+	}
+
+	ErrNotEnoughReviewers = Error{
+		Code:    "NOT_ENOUGH_REVIEWERS",
+		Message: "team has fewer active reviewers than its configured minimum",
+	}
+
+	ErrUnauthorized = Error{
+		Code:    "UNAUTHORIZED",
+		Message: "missing or invalid credentials",
+	}
+
+	ErrForbidden = Error{
+		Code:    "FORBIDDEN",
+		Message: "caller is not allowed to perform this action",
+	}
+
 	ErrNotFound = Error{
 		Code:    "NOT_FOUND",
 		Message: "resource not found",
@@ -1,25 +1,75 @@
 package domain
 
 type TeamRequest struct {
+	TeamName           string `json:"team_name"`
+	AssignmentStrategy string `json:"assignment_strategy,omitempty"`
+	Members            []User `json:"members"`
+}
+
+type TeamSettingsRequest struct {
+	TeamName           string `json:"team_name"`
+	AssignmentStrategy string `json:"assignment_strategy,omitempty"`
+	MinReviewers       *int   `json:"min_reviewers,omitempty"`
+	MaxReviewers       *int   `json:"max_reviewers,omitempty"`
+}
+
+type AddMembersRequest struct {
 	TeamName string `json:"team_name"`
 	Members  []User `json:"members"`
 }
 
+type RemoveMembersRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
+type MoveMemberRequest struct {
+	UserID   string `json:"user_id"`
+	FromTeam string `json:"from_team"`
+	ToTeam   string `json:"to_team"`
+}
+
+type RenameTeamRequest struct {
+	TeamName string `json:"team_name"`
+	NewName  string `json:"new_name"`
+}
+
+type DeleteTeamRequest struct {
+	TeamName string `json:"team_name"`
+	// Force deletes a team that still has OPEN PRs. They are closed, or moved
+	// to ReassignTo when it is set.
+	Force      bool   `json:"force,omitempty"`
+	ReassignTo string `json:"reassign_to,omitempty"`
+}
+
 type SetActiveRequest struct {
 	UserID   string `json:"user_id"`
 	IsActive bool   `json:"is_active"`
 }
 
+type DeactivateUsersRequest struct {
+	TeamName string   `json:"team_name"`
+	UserIDs  []string `json:"user_ids"`
+}
+
 type CreatePRRequest struct {
 	PRID     string `json:"pull_request_id"`
 	PRName   string `json:"pull_request_name"`
 	AuthorID string `json:"author_id"`
+	// TeamName picks the team reviewing the PR. It may be left out when the
+	// author belongs to a single team.
+	TeamName string `json:"team_name,omitempty"`
+	Draft    bool   `json:"draft,omitempty"`
 }
 
 type SetMergedRequest struct {
 	PrID string `json:"pull_request_id"`
 }
 
+type PRStatusRequest struct {
+	PrID string `json:"pull_request_id"`
+}
+
 type ReassignRequest struct {
 	PrID   string `json:"pull_request_id"`
 	UserID string `json:"old_reviewer_id"`
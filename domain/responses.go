@@ -10,6 +10,22 @@ type TeamResponse struct {
 	Team TeamRequest `json:"team"`
 }
 
+type TeamSettingsResponse struct {
+	Settings TeamSettings `json:"settings"`
+}
+
+type ListTeamsResponse struct {
+	Teams []TeamSummary `json:"teams"`
+}
+
+type DeleteTeamResponse struct {
+	TeamName      string         `json:"team_name"`
+	ClosedPRs     []string       `json:"closed_prs"`
+	ReassignedPRs []string       `json:"reassigned_prs"`
+	ReassignedTo  string         `json:"reassigned_to,omitempty"`
+	Reassignments []Reassignment `json:"reassigned_reviews"`
+}
+
 func WriteResponse(w http.ResponseWriter, statusCode int, data any) error {
 	w.Header().Set(ContentType, ApplicationJSON)
 	w.WriteHeader(statusCode)
@@ -18,14 +34,25 @@ func WriteResponse(w http.ResponseWriter, statusCode int, data any) error {
 }
 
 type SetActiveResponse struct {
-	User setActiveUser `json:"user"`
+	User          setActiveUser  `json:"user"`
+	Reassignments []Reassignment `json:"reassigned_reviews,omitempty"`
 }
 
 type setActiveUser struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
-	TeamName string `json:"team_name"`
-	IsActive bool   `json:"is_active"`
+	// TeamName is the first of Teams, kept for clients that expect a single
+	// team per user.
+	TeamName string   `json:"team_name"`
+	Teams    []string `json:"teams"`
+	IsActive bool     `json:"is_active"`
+}
+
+type DeactivateUsersResponse struct {
+	TeamName         string         `json:"team_name"`
+	DeactivatedUsers []string       `json:"deactivated_users"`
+	Reassignments    []Reassignment `json:"reassigned_reviews"`
+	UnderstaffedPRs  []string       `json:"understaffed_prs"`
 }
 
 type CutPullRequest struct {
@@ -49,7 +76,33 @@ type MergePRResponse struct {
 	MergedAt time.Time   `json:"mergedAt"`
 }
 
+type PRResponse struct {
+	PR PullRequest `json:"pr"`
+}
+
+type PRHistoryResponse struct {
+	PrID   string            `json:"pull_request_id"`
+	Events []AssignmentEvent `json:"events"`
+}
+
+type ListPRResponse struct {
+	PullRequests []PullRequest `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
 type ReassignPRResponse struct {
 	PR         PullRequest `json:"pr"`
 	ReplacedBy string      `json:"replaced_by"`
 }
+
+type AssignmentStatsResponse struct {
+	From  *time.Time            `json:"from,omitempty"`
+	To    *time.Time            `json:"to,omitempty"`
+	Users []UserAssignmentStats `json:"users"`
+	Teams []TeamAssignmentStats `json:"teams"`
+}
+
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE teams
+    ADD COLUMN assignment_strategy VARCHAR(20) NOT NULL DEFAULT 'random'
+    CHECK (assignment_strategy IN ('random', 'round_robin', 'least_loaded'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE teams DROP COLUMN IF EXISTS assignment_strategy;
+-- +goose StatementEnd
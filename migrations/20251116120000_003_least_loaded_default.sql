@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE teams ALTER COLUMN assignment_strategy SET DEFAULT 'least_loaded';
+
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_user_id ON pr_reviewers (user_id);
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_status ON pull_requests (status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pull_requests_status;
+
+DROP INDEX IF EXISTS idx_pr_reviewers_user_id;
+
+ALTER TABLE teams ALTER COLUMN assignment_strategy SET DEFAULT 'random';
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE teams
+    ADD COLUMN min_reviewers INT NOT NULL DEFAULT 1 CHECK (min_reviewers >= 0),
+    ADD COLUMN max_reviewers INT NOT NULL DEFAULT 2 CHECK (max_reviewers >= 1),
+    ADD CONSTRAINT teams_reviewers_range CHECK (min_reviewers <= max_reviewers);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS teams_reviewers_range,
+    DROP COLUMN IF EXISTS max_reviewers,
+    DROP COLUMN IF EXISTS min_reviewers;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pull_requests ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_created_at ON pull_requests (created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_author_id ON pull_requests (author_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pull_requests_author_id;
+
+DROP INDEX IF EXISTS idx_pull_requests_created_at;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS created_at;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE review_assignments (
+    id BIGSERIAL PRIMARY KEY,
+    pr_id VARCHAR(20) NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    event VARCHAR(20) NOT NULL CHECK (event IN ('ASSIGNED', 'REASSIGNED', 'UNASSIGNED')),
+    old_reviewer_id VARCHAR(20),
+    new_reviewer_id VARCHAR(20),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_review_assignments_pr_id ON review_assignments (pr_id);
+
+CREATE INDEX idx_review_assignments_created_at ON review_assignments (created_at);
+
+INSERT INTO review_assignments (pr_id, event, new_reviewer_id, created_at)
+SELECT rv.pr_id, 'ASSIGNED', rv.user_id, pr.created_at
+FROM pr_reviewers rv
+JOIN pull_requests pr ON pr.id = rv.pr_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS review_assignments;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE review_assignments
+    ADD COLUMN actor VARCHAR(100) NOT NULL DEFAULT 'system',
+    ADD COLUMN reason VARCHAR(50) NOT NULL DEFAULT 'backfill';
+
+ALTER TABLE review_assignments DROP CONSTRAINT IF EXISTS review_assignments_event_check;
+
+ALTER TABLE review_assignments
+    ADD CONSTRAINT review_assignments_event_check
+    CHECK (event IN ('ASSIGNED', 'REASSIGNED', 'UNASSIGNED', 'MERGED'));
+
+CREATE FUNCTION review_assignments_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'review_assignments is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER review_assignments_no_update
+    BEFORE UPDATE ON review_assignments
+    FOR EACH ROW EXECUTE FUNCTION review_assignments_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS review_assignments_no_update ON review_assignments;
+
+DROP FUNCTION IF EXISTS review_assignments_append_only();
+
+DELETE FROM review_assignments WHERE event = 'MERGED';
+
+ALTER TABLE review_assignments DROP CONSTRAINT IF EXISTS review_assignments_event_check;
+
+ALTER TABLE review_assignments
+    ADD CONSTRAINT review_assignments_event_check
+    CHECK (event IN ('ASSIGNED', 'REASSIGNED', 'UNASSIGNED'));
+
+ALTER TABLE review_assignments
+    DROP COLUMN IF EXISTS reason,
+    DROP COLUMN IF EXISTS actor;
+-- +goose StatementEnd
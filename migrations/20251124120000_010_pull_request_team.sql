@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pull_requests ADD COLUMN team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL;
+
+-- Until now a PR was reviewed by its author's team; for authors in several
+-- teams that was the earliest created one.
+UPDATE pull_requests pr
+SET team_id = (
+    SELECT MIN(tm.team_id)
+    FROM team_members tm
+    WHERE tm.user_id = pr.author_id
+);
+
+CREATE INDEX IF NOT EXISTS idx_pull_requests_team_id ON pull_requests (team_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pull_requests_team_id;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS team_id;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS review_assignments_no_update ON review_assignments;
+
+CREATE TRIGGER review_assignments_no_update_or_delete
+    BEFORE UPDATE OR DELETE ON review_assignments
+    FOR EACH ROW EXECUTE FUNCTION review_assignments_append_only();
+
+CREATE TRIGGER review_assignments_no_truncate
+    BEFORE TRUNCATE ON review_assignments
+    FOR EACH STATEMENT EXECUTE FUNCTION review_assignments_append_only();
+
+-- Deleting a PR must not take its history with it.
+ALTER TABLE review_assignments
+    DROP CONSTRAINT review_assignments_pr_id_fkey,
+    ADD CONSTRAINT review_assignments_pr_id_fkey
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE RESTRICT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE review_assignments
+    DROP CONSTRAINT review_assignments_pr_id_fkey,
+    ADD CONSTRAINT review_assignments_pr_id_fkey
+    FOREIGN KEY (pr_id) REFERENCES pull_requests(id) ON DELETE CASCADE;
+
+DROP TRIGGER IF EXISTS review_assignments_no_truncate ON review_assignments;
+
+DROP TRIGGER IF EXISTS review_assignments_no_update_or_delete ON review_assignments;
+
+CREATE TRIGGER review_assignments_no_update
+    BEFORE UPDATE ON review_assignments
+    FOR EACH ROW EXECUTE FUNCTION review_assignments_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	apikeyrepo "github.com/dafuqqqyunglean/avito_tech/database/apikey"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
+)
+
+type Service interface {
+	Authenticate(ctx context.Context, key string) (domain.Principal, error)
+	AuthenticateToken(ctx context.Context, token string) (domain.Principal, error)
+	CreateKey(ctx context.Context, name, role, teamName string) (string, domain.APIKey, error)
+	ListKeys(ctx context.Context) ([]domain.APIKey, error)
+	RevokeKey(ctx context.Context, id int64) error
+}
+
+const (
+	keyPrefix     = "prs_"
+	keyBytes      = 32
+	maxNameLength = 100
+)
+
+type impl struct {
+	repo     apikeyrepo.Repository
+	verifier *TokenVerifier
+}
+
+// NewService creates the auth service. Bearer tokens are rejected when
+// verifier is nil.
+func NewService(repo apikeyrepo.Repository, verifier *TokenVerifier) Service {
+	return &impl{
+		repo:     repo,
+		verifier: verifier,
+	}
+}
+
+// Authenticate resolves an API key to its caller. Unknown and revoked keys
+// yield domain.ErrUnauthorized.
+func (s *impl) Authenticate(ctx context.Context, key string) (domain.Principal, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.Authenticate")
+	defer span.End()
+
+	apiKey, err := s.repo.GetActive(ctx, hashKey(key))
+	if errors.Is(err, domain.ErrNotFound) {
+		slog.WarnContext(ctx, "rejected unknown or revoked api key")
+
+		return domain.Principal{}, domain.ErrUnauthorized
+	}
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	return domain.Principal{
+		Name:     "key:" + apiKey.Name,
+		Role:     apiKey.Role,
+		TeamName: apiKey.TeamName,
+	}, nil
+}
+
+// AuthenticateToken resolves a bearer JWT to the user it was issued to. Any
+// token that fails verification yields domain.ErrUnauthorized.
+func (s *impl) AuthenticateToken(ctx context.Context, token string) (domain.Principal, error) {
+	ctx, span := tracing.Start(ctx, "auth.Service.AuthenticateToken")
+	defer span.End()
+
+	if s.verifier == nil {
+		slog.WarnContext(ctx, "rejected bearer token, token authentication is not configured")
+
+		return domain.Principal{}, domain.ErrUnauthorized
+	}
+
+	principal, err := s.verifier.Verify(ctx, token)
+	if err != nil {
+		slog.WarnContext(ctx, "rejected bearer token", "error", err)
+
+		return domain.Principal{}, domain.ErrUnauthorized
+	}
+
+	return principal, nil
+}
+
+// CreateKey mints a new key and returns it in plain text together with its
+// stored description. The plain key cannot be recovered later.
+func (s *impl) CreateKey(ctx context.Context, name, role, teamName string) (string, domain.APIKey, error) {
+	if err := validateKey(name, role, teamName); err != nil {
+		slog.ErrorContext(ctx, "api key validation failed",
+			"name", name,
+			"role", role,
+			"error", err)
+
+		return "", domain.APIKey{}, domain.ErrBadRequest
+	}
+
+	secret := make([]byte, keyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", domain.APIKey{}, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := keyPrefix + hex.EncodeToString(secret)
+
+	apiKey, err := s.repo.Create(ctx, name, hashKey(key), role, teamName)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create api key",
+			"name", name,
+			"error", err)
+
+		return "", domain.APIKey{}, err
+	}
+
+	slog.InfoContext(ctx, "api key created",
+		"id", apiKey.ID,
+		"name", apiKey.Name,
+		"role", apiKey.Role)
+
+	return key, apiKey, nil
+}
+
+func (s *impl) ListKeys(ctx context.Context) ([]domain.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *impl) RevokeKey(ctx context.Context, id int64) error {
+	if err := s.repo.Revoke(ctx, id); err != nil {
+		slog.ErrorContext(ctx, "failed to revoke api key",
+			"id", id,
+			"error", err)
+
+		return err
+	}
+
+	slog.InfoContext(ctx, "api key revoked", "id", id)
+
+	return nil
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func validateKey(name, role, teamName string) error {
+	if strings.TrimSpace(name) == "" || len(name) > maxNameLength {
+		return fmt.Errorf("name must be 1 to %d characters", maxNameLength)
+	}
+
+	switch role {
+	case domain.RoleTeamMaintainer:
+		if teamName == "" {
+			return fmt.Errorf("%s keys require a team", role)
+		}
+	case domain.RoleAdmin, domain.RoleReader, domain.RoleBot:
+		if teamName != "" {
+			return fmt.Errorf("%s keys are not bound to a team", role)
+		}
+	default:
+		return fmt.Errorf("unknown role: %s", role)
+	}
+
+	return nil
+}
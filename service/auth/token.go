@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dafuqqqyunglean/avito_tech/config"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	tokenLeeway         = 30 * time.Second
+	jwksFetchTimeout    = 10 * time.Second
+	jwksRefreshInterval = time.Minute
+	maxJWKSSize         = 1 << 20
+)
+
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// rolePriority decides which role a token gets when it carries several.
+var rolePriority = []string{domain.RoleAdmin, domain.RoleTeamMaintainer, domain.RoleBot, domain.RoleReader}
+
+// TokenVerifier checks bearer JWTs against a JWKS and maps their claims to a
+// caller: the user claim holds the service's user ID, the role claim one or
+// more roles (reader when absent) and the team claim the team of a
+// team-maintainer.
+type TokenVerifier struct {
+	keys      *keySet
+	expected  jwt.Expected
+	userClaim string
+	roleClaim string
+	teamClaim string
+}
+
+func NewTokenVerifier(ctx context.Context, config config.JWTConfig) (*TokenVerifier, error) {
+	keys := &keySet{
+		source:          config.JWKS,
+		client:          &http.Client{Timeout: jwksFetchTimeout},
+		refreshInterval: jwksRefreshInterval,
+	}
+	if err := keys.load(ctx); err != nil {
+		return nil, err
+	}
+
+	return &TokenVerifier{
+		keys: keys,
+		expected: jwt.Expected{
+			Issuer:      config.Issuer,
+			AnyAudience: jwt.Audience{config.Audience},
+		},
+		userClaim: config.UserClaim,
+		roleClaim: config.RoleClaim,
+		teamClaim: config.TeamClaim,
+	}, nil
+}
+
+// Verify checks the token's signature, issuer, audience and expiry and returns
+// the caller it was issued to.
+func (v *TokenVerifier) Verify(ctx context.Context, raw string) (domain.Principal, error) {
+	token, err := jwt.ParseSigned(raw, signatureAlgorithms)
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("malformed token: %w", err)
+	}
+
+	key, err := v.keys.key(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	var registered jwt.Claims
+	var custom map[string]any
+	if err := token.Claims(key, &registered, &custom); err != nil {
+		return domain.Principal{}, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	if registered.Expiry == nil {
+		return domain.Principal{}, errors.New("token has no expiry")
+	}
+
+	if err := registered.ValidateWithLeeway(v.expected.WithTime(time.Now()), tokenLeeway); err != nil {
+		return domain.Principal{}, err
+	}
+
+	userID, _ := custom[v.userClaim].(string)
+	if strings.TrimSpace(userID) == "" {
+		return domain.Principal{}, fmt.Errorf("token has no %s claim", v.userClaim)
+	}
+
+	principal := domain.Principal{
+		Name: userID,
+		Role: claimRole(custom[v.roleClaim]),
+	}
+
+	if principal.Role == domain.RoleTeamMaintainer {
+		principal.TeamName, _ = custom[v.teamClaim].(string)
+		if principal.TeamName == "" {
+			return domain.Principal{}, fmt.Errorf("team-maintainer token has no %s claim", v.teamClaim)
+		}
+	}
+
+	return principal, nil
+}
+
+func claimRole(claim any) string {
+	var roles []string
+	switch value := claim.(type) {
+	case string:
+		roles = append(roles, value)
+	case []any:
+		for _, item := range value {
+			if role, ok := item.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+
+	for _, role := range rolePriority {
+		if slices.Contains(roles, role) {
+			return role
+		}
+	}
+
+	return domain.RoleReader
+}
+
+// keySet holds the verification keys loaded from a file or an http(s) URL. A
+// URL is fetched again when a token names an unknown key, at most once per
+// refreshInterval, so the issuer can rotate keys without a restart.
+type keySet struct {
+	source          string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	mu       sync.Mutex
+	keys     jose.JSONWebKeySet
+	loadedAt time.Time
+}
+
+func (s *keySet) key(ctx context.Context, keyID string) (jose.JSONWebKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.lookup(keyID); ok {
+		return key, nil
+	}
+
+	if !s.remote() || time.Since(s.loadedAt) < s.refreshInterval {
+		return jose.JSONWebKey{}, fmt.Errorf("unknown signing key %q", keyID)
+	}
+
+	if err := s.fetch(ctx); err != nil {
+		return jose.JSONWebKey{}, err
+	}
+
+	if key, ok := s.lookup(keyID); ok {
+		return key, nil
+	}
+
+	return jose.JSONWebKey{}, fmt.Errorf("unknown signing key %q", keyID)
+}
+
+func (s *keySet) lookup(keyID string) (jose.JSONWebKey, bool) {
+	candidates := s.keys.Key(keyID)
+	if keyID == "" && len(s.keys.Keys) == 1 {
+		candidates = s.keys.Keys
+	}
+
+	if len(candidates) == 0 {
+		return jose.JSONWebKey{}, false
+	}
+
+	return candidates[0].Public(), true
+}
+
+func (s *keySet) remote() bool {
+	return strings.HasPrefix(s.source, "http://") || strings.HasPrefix(s.source, "https://")
+}
+
+func (s *keySet) load(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.remote() {
+		return s.fetch(ctx)
+	}
+
+	data, err := os.ReadFile(s.source)
+	if err != nil {
+		return fmt.Errorf("failed to read jwks file: %w", err)
+	}
+
+	return s.parse(data)
+}
+
+func (s *keySet) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build jwks request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch jwks: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return fmt.Errorf("failed to read jwks: %w", err)
+	}
+
+	return s.parse(data)
+}
+
+func (s *keySet) parse(data []byte) error {
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("failed to parse jwks: %w", err)
+	}
+
+	if len(keys.Keys) == 0 {
+		return errors.New("jwks has no keys")
+	}
+
+	s.keys = keys
+	s.loadedAt = time.Now()
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dafuqqqyunglean/avito_tech/config"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "pr-service"
+)
+
+func newTestKey(t *testing.T, keyID string) jose.JSONWebKey {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	return jose.JSONWebKey{Key: private, KeyID: keyID, Algorithm: string(jose.ES256), Use: "sig"}
+}
+
+func writeJWKS(t *testing.T, keys ...jose.JSONWebKey) string {
+	t.Helper()
+
+	data := marshalJWKS(t, keys...)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+
+	return path
+}
+
+func marshalJWKS(t *testing.T, keys ...jose.JSONWebKey) []byte {
+	t.Helper()
+
+	set := jose.JSONWebKeySet{}
+	for _, key := range keys {
+		set.Keys = append(set.Keys, key.Public())
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+
+	return data
+}
+
+func signToken(t *testing.T, key jose.JSONWebKey, claims jwt.Claims, custom map[string]any) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		t.Fatalf("new signer: %v", err)
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).Claims(custom).Serialize()
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	return token
+}
+
+func validClaims() jwt.Claims {
+	now := time.Now()
+
+	return jwt.Claims{
+		Issuer:   testIssuer,
+		Audience: jwt.Audience{testAudience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+}
+
+func newTestVerifier(t *testing.T, jwks string) *TokenVerifier {
+	t.Helper()
+
+	verifier, err := NewTokenVerifier(context.Background(), config.JWTConfig{
+		JWKS:      jwks,
+		Issuer:    testIssuer,
+		Audience:  testAudience,
+		UserClaim: "sub",
+		RoleClaim: "roles",
+		TeamClaim: "team",
+	})
+	if err != nil {
+		t.Fatalf("new verifier: %v", err)
+	}
+
+	return verifier
+}
+
+func TestTokenVerifierMapsClaims(t *testing.T) {
+	key := newTestKey(t, "k1")
+	verifier := newTestVerifier(t, writeJWKS(t, key))
+
+	tests := []struct {
+		name   string
+		custom map[string]any
+		want   domain.Principal
+	}{
+		{
+			name:   "no role claim",
+			custom: map[string]any{"sub": "u1"},
+			want:   domain.Principal{Name: "u1", Role: domain.RoleReader},
+		},
+		{
+			name:   "highest of several roles",
+			custom: map[string]any{"sub": "u2", "roles": []string{"reader", "admin", "unknown"}},
+			want:   domain.Principal{Name: "u2", Role: domain.RoleAdmin},
+		},
+		{
+			name:   "team maintainer",
+			custom: map[string]any{"sub": "u3", "roles": "team-maintainer", "team": "backend"},
+			want:   domain.Principal{Name: "u3", Role: domain.RoleTeamMaintainer, TeamName: "backend"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifier.Verify(context.Background(), signToken(t, key, validClaims(), tt.custom))
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenVerifierRejectsInvalidTokens(t *testing.T) {
+	key := newTestKey(t, "k1")
+	verifier := newTestVerifier(t, writeJWKS(t, key))
+	subject := map[string]any{"sub": "u1"}
+
+	expired := validClaims()
+	expired.IssuedAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Hour))
+	expired.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	noExpiry := validClaims()
+	noExpiry.Expiry = nil
+
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "https://evil.example.com"
+
+	wrongAudience := validClaims()
+	wrongAudience.Audience = jwt.Audience{"another-service"}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", signToken(t, key, expired, subject)},
+		{"no expiry", signToken(t, key, noExpiry, subject)},
+		{"wrong issuer", signToken(t, key, wrongIssuer, subject)},
+		{"wrong audience", signToken(t, key, wrongAudience, subject)},
+		{"no user claim", signToken(t, key, validClaims(), map[string]any{"roles": "admin"})},
+		{"maintainer without team", signToken(t, key, validClaims(), map[string]any{"sub": "u1", "roles": "team-maintainer"})},
+		{"foreign key with known id", signToken(t, newTestKey(t, "k1"), validClaims(), subject)},
+		{"unknown key id", signToken(t, newTestKey(t, "k2"), validClaims(), subject)},
+		{"malformed", "not.a.token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if principal, err := verifier.Verify(context.Background(), tt.token); err == nil {
+				t.Errorf("got principal %+v, want error", principal)
+			}
+		})
+	}
+}
+
+func TestTokenVerifierRefetchesRotatedKeys(t *testing.T) {
+	oldKey := newTestKey(t, "old")
+	newKey := newTestKey(t, "new")
+
+	jwks := marshalJWKS(t, oldKey)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(jwks)
+	}))
+	defer server.Close()
+
+	verifier := newTestVerifier(t, server.URL)
+	verifier.keys.refreshInterval = 0
+
+	if _, err := verifier.Verify(context.Background(), signToken(t, oldKey, validClaims(), map[string]any{"sub": "u1"})); err != nil {
+		t.Fatalf("verify with old key: %v", err)
+	}
+
+	jwks = marshalJWKS(t, newKey)
+
+	got, err := verifier.Verify(context.Background(), signToken(t, newKey, validClaims(), map[string]any{"sub": "u2"}))
+	if err != nil {
+		t.Fatalf("verify with rotated key: %v", err)
+	}
+	if got.Name != "u2" {
+		t.Errorf("got user %q, want u2", got.Name)
+	}
+}
@@ -0,0 +1,128 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	healthrepo "github.com/dafuqqqyunglean/avito_tech/database/health"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/pressly/goose/v3"
+)
+
+const (
+	checkDatabase   = "database"
+	checkMigrations = "migrations"
+	statusOK        = "ok"
+	statusFailed    = "unavailable"
+	statusOutdated  = "outdated"
+	statusSkipped   = "skipped"
+)
+
+// errSchemaOutdated is reported as statusOutdated rather than statusFailed.
+var errSchemaOutdated = errors.New("schema is not at the latest migration")
+
+type Service interface {
+	Live(ctx context.Context) domain.HealthResponse
+	Ready(ctx context.Context) (domain.HealthResponse, bool)
+}
+
+// LatestMigration returns the version of the newest migration shipped with the
+// binary.
+type LatestMigration func() (int64, error)
+
+// MigrationsDir reads the newest migration from a directory of goose files.
+func MigrationsDir(dir string) LatestMigration {
+	return func() (int64, error) {
+		migrations, err := goose.CollectMigrations(dir, 0, goose.MaxVersion)
+		if err != nil {
+			return 0, fmt.Errorf("failed to collect migrations: %w", err)
+		}
+
+		latest, err := migrations.Last()
+		if err != nil {
+			return 0, fmt.Errorf("failed to find latest migration: %w", err)
+		}
+
+		return latest.Version, nil
+	}
+}
+
+type impl struct {
+	repo            healthrepo.Repository
+	latestMigration LatestMigration
+}
+
+func NewService(repo healthrepo.Repository, latestMigration LatestMigration) Service {
+	return &impl{
+		repo:            repo,
+		latestMigration: latestMigration,
+	}
+}
+
+func (s *impl) Live(ctx context.Context) domain.HealthResponse {
+	return domain.HealthResponse{Status: statusOK}
+}
+
+// Ready reports whether the service can take traffic: the database answers and
+// its schema is at the newest migration shipped with the binary. The probe is
+// unauthenticated, so failures are reported as fixed values and the errors
+// themselves only go to the log.
+func (s *impl) Ready(ctx context.Context) (domain.HealthResponse, bool) {
+	resp := domain.HealthResponse{
+		Status: statusOK,
+		Checks: map[string]string{
+			checkDatabase:   statusOK,
+			checkMigrations: statusOK,
+		},
+	}
+
+	if err := s.repo.Ping(ctx); err != nil {
+		slog.ErrorContext(ctx, "readiness check failed", "check", checkDatabase, "error", err)
+
+		resp.Status = statusFailed
+		resp.Checks[checkDatabase] = statusFailed
+		resp.Checks[checkMigrations] = statusSkipped
+
+		return resp, false
+	}
+
+	if err := s.checkMigrations(ctx); err != nil {
+		slog.ErrorContext(ctx, "readiness check failed", "check", checkMigrations, "error", err)
+
+		resp.Status = statusFailed
+		resp.Checks[checkMigrations] = statusFailed
+		if errors.Is(err, errSchemaOutdated) {
+			resp.Checks[checkMigrations] = statusOutdated
+		}
+
+		return resp, false
+	}
+
+	return resp, true
+}
+
+// checkMigrations is skipped when latestMigration is nil, as for storage that
+// has no schema.
+func (s *impl) checkMigrations(ctx context.Context) error {
+	if s.latestMigration == nil {
+		return nil
+	}
+
+	latest, err := s.latestMigration()
+	if err != nil {
+		return err
+	}
+
+	current, err := s.repo.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if current != latest {
+		return fmt.Errorf("%w: at version %d, expected %d", errSchemaOutdated, current, latest)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package pr
+
+import (
+	"math/rand/v2"
+	"sort"
+	"sync"
+
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+// ReviewerSelector picks up to count reviewers out of the team's candidates.
+type ReviewerSelector interface {
+	Select(pool domain.ReviewerPool, count int) []string
+}
+
+// Selectors maps a team assignment strategy to its selector. The same set is
+// shared by every service that assigns reviewers, so stateful strategies such
+// as round-robin keep a single cursor per team.
+type Selectors map[string]ReviewerSelector
+
+func NewSelectors() Selectors {
+	return Selectors{
+		domain.StrategyRandom:      NewRandomSelector(),
+		domain.StrategyRoundRobin:  NewRoundRobinSelector(),
+		domain.StrategyLeastLoaded: NewLeastLoadedSelector(),
+	}
+}
+
+// Select uses the pool's strategy, falling back to least-loaded for unknown ones.
+func (s Selectors) Select(pool domain.ReviewerPool, count int) []string {
+	if selector, ok := s[pool.Strategy]; ok {
+		return selector.Select(pool, count)
+	}
+
+	return s[domain.StrategyLeastLoaded].Select(pool, count)
+}
+
+type randomSelector struct{}
+
+func NewRandomSelector() ReviewerSelector {
+	return randomSelector{}
+}
+
+func (randomSelector) Select(pool domain.ReviewerPool, count int) []string {
+	candidates := make([]domain.ReviewCandidate, len(pool.Candidates))
+	copy(candidates, pool.Candidates)
+
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	return pickIDs(candidates, count)
+}
+
+// roundRobinSelector keeps a cursor per team, so consecutive PRs of the same
+// team walk through its members in user ID order.
+type roundRobinSelector struct {
+	mu      sync.Mutex
+	cursors map[string]int
+}
+
+func NewRoundRobinSelector() ReviewerSelector {
+	return &roundRobinSelector{
+		cursors: make(map[string]int),
+	}
+}
+
+func (s *roundRobinSelector) Select(pool domain.ReviewerPool, count int) []string {
+	if len(pool.Candidates) == 0 {
+		return []string{}
+	}
+
+	candidates := make([]domain.ReviewCandidate, len(pool.Candidates))
+	copy(candidates, pool.Candidates)
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].UserID < candidates[j].UserID
+	})
+
+	s.mu.Lock()
+	start := s.cursors[pool.TeamName] % len(candidates)
+	s.cursors[pool.TeamName] = start + min(count, len(candidates))
+	s.mu.Unlock()
+
+	rotated := make([]domain.ReviewCandidate, 0, len(candidates))
+	rotated = append(rotated, candidates[start:]...)
+	rotated = append(rotated, candidates[:start]...)
+
+	return pickIDs(rotated, count)
+}
+
+// leastLoadedSelector prefers candidates with the fewest open reviews. Ties are
+// broken at random, so equally loaded teammates share new reviews evenly.
+type leastLoadedSelector struct{}
+
+func NewLeastLoadedSelector() ReviewerSelector {
+	return leastLoadedSelector{}
+}
+
+func (leastLoadedSelector) Select(pool domain.ReviewerPool, count int) []string {
+	candidates := make([]domain.ReviewCandidate, len(pool.Candidates))
+	copy(candidates, pool.Candidates)
+
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].OpenReviews < candidates[j].OpenReviews
+	})
+
+	return pickIDs(candidates, count)
+}
+
+func pickIDs(candidates []domain.ReviewCandidate, count int) []string {
+	ids := make([]string, 0, min(count, len(candidates)))
+	for _, candidate := range candidates[:min(count, len(candidates))] {
+		ids = append(ids, candidate.UserID)
+	}
+
+	return ids
+}
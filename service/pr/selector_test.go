@@ -0,0 +1,188 @@
+package pr
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+)
+
+// runs is how many times the randomized selectors are asked before a test
+// expects every allowed outcome to have shown up.
+const runs = 200
+
+func candidate(userID string, openReviews int) domain.ReviewCandidate {
+	return domain.ReviewCandidate{UserID: userID, OpenReviews: openReviews}
+}
+
+func TestSelectorsPickFromCandidates(t *testing.T) {
+	// The author is never a candidate: repositories leave them out of the
+	// pool, and no strategy may pick anyone outside it.
+	const author = "a"
+	pool := domain.ReviewerPool{
+		TeamName:   "backend",
+		Candidates: []domain.ReviewCandidate{candidate("b", 1), candidate("c", 0), candidate("d", 2)},
+	}
+
+	tests := []struct {
+		name  string
+		count int
+		want  int
+	}{
+		{"none", 0, 0},
+		{"one", 1, 1},
+		{"several", 2, 2},
+		{"all", 3, 3},
+		{"more than candidates", 5, 3},
+	}
+
+	strategies := []string{domain.StrategyRandom, domain.StrategyRoundRobin, domain.StrategyLeastLoaded, "unknown"}
+	for _, strategy := range strategies {
+		for _, tt := range tests {
+			t.Run(strategy+"/"+tt.name, func(t *testing.T) {
+				pool := pool
+				pool.Strategy = strategy
+
+				got := NewSelectors().Select(pool, tt.count)
+				if len(got) != tt.want {
+					t.Fatalf("got %v, want %d reviewers", got, tt.want)
+				}
+
+				seen := make(map[string]bool)
+				for _, id := range got {
+					if id == author {
+						t.Fatalf("got %v, picked the author", got)
+					}
+					if !slices.ContainsFunc(pool.Candidates, func(c domain.ReviewCandidate) bool { return c.UserID == id }) {
+						t.Fatalf("got %v, %s is not a candidate", got, id)
+					}
+					if seen[id] {
+						t.Fatalf("got %v, %s picked twice", got, id)
+					}
+					seen[id] = true
+				}
+			})
+		}
+	}
+}
+
+func TestSelectorsEmptyPool(t *testing.T) {
+	for _, strategy := range []string{domain.StrategyRandom, domain.StrategyRoundRobin, domain.StrategyLeastLoaded} {
+		t.Run(strategy, func(t *testing.T) {
+			got := NewSelectors().Select(domain.ReviewerPool{TeamName: "backend", Strategy: strategy}, 2)
+			if got == nil || len(got) != 0 {
+				t.Fatalf("got %#v, want an empty slice", got)
+			}
+		})
+	}
+}
+
+func TestRandomSelectorPicksEveryone(t *testing.T) {
+	pool := domain.ReviewerPool{
+		Candidates: []domain.ReviewCandidate{candidate("b", 0), candidate("c", 5), candidate("d", 9)},
+	}
+
+	seen := make(map[string]bool)
+	for range runs {
+		for _, id := range NewRandomSelector().Select(pool, 1) {
+			seen[id] = true
+		}
+	}
+
+	if len(seen) != len(pool.Candidates) {
+		t.Fatalf("picked %v in %d runs, want every candidate regardless of load", seen, runs)
+	}
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	tests := []struct {
+		name  string
+		team  string
+		count int
+		want  []string
+	}{
+		{"starts at the lowest user ID", "backend", 2, []string{"b", "c"}},
+		{"wraps around", "backend", 2, []string{"d", "b"}},
+		{"continues from the cursor", "backend", 1, []string{"c"}},
+		{"keeps a cursor per team", "frontend", 1, []string{"b"}},
+		{"takes everyone at most once", "backend", 5, []string{"d", "b", "c"}},
+	}
+
+	// Candidates come in any order; the walk follows user IDs.
+	selector := NewRoundRobinSelector()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := domain.ReviewerPool{
+				TeamName:   tt.team,
+				Candidates: []domain.ReviewCandidate{candidate("d", 0), candidate("b", 3), candidate("c", 1)},
+			}
+
+			if got := selector.Select(pool, tt.count); !slices.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeastLoadedSelector(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []domain.ReviewCandidate
+		count      int
+		allowed    [][]string
+	}{
+		{
+			name:       "prefers the fewest open reviews",
+			candidates: []domain.ReviewCandidate{candidate("b", 2), candidate("c", 0), candidate("d", 1)},
+			count:      2,
+			allowed:    [][]string{{"c", "d"}},
+		},
+		{
+			name:       "breaks ties at random",
+			candidates: []domain.ReviewCandidate{candidate("b", 1), candidate("c", 1), candidate("d", 4)},
+			count:      1,
+			allowed:    [][]string{{"b"}, {"c"}},
+		},
+		{
+			name:       "fills up from the next load",
+			candidates: []domain.ReviewCandidate{candidate("b", 3), candidate("c", 0), candidate("d", 3)},
+			count:      2,
+			allowed:    [][]string{{"c", "b"}, {"c", "d"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := domain.ReviewerPool{Candidates: tt.candidates}
+
+			seen := make([]bool, len(tt.allowed))
+			for range runs {
+				got := NewLeastLoadedSelector().Select(pool, tt.count)
+
+				i := slices.IndexFunc(tt.allowed, func(want []string) bool { return slices.Equal(got, want) })
+				if i < 0 {
+					t.Fatalf("got %v, want one of %v", got, tt.allowed)
+				}
+				seen[i] = true
+			}
+
+			if slices.Contains(seen, false) {
+				t.Fatalf("saw %v of %v in %d runs, want every tie to win", seen, tt.allowed, runs)
+			}
+		})
+	}
+}
+
+func TestSelectorsFallBackToLeastLoaded(t *testing.T) {
+	pool := domain.ReviewerPool{
+		TeamName:   "backend",
+		Strategy:   "unknown",
+		Candidates: []domain.ReviewCandidate{candidate("b", 2), candidate("c", 0), candidate("d", 1)},
+	}
+
+	for range runs {
+		if got := NewSelectors().Select(pool, 2); !slices.Equal(got, []string{"c", "d"}) {
+			t.Fatalf("got %v, want the least loaded [c d]", got)
+		}
+	}
+}
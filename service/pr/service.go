@@ -2,53 +2,105 @@ package pr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	prrepo "github.com/dafuqqqyunglean/avito_tech/database/pr"
 	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/metrics"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
 )
 
 type Service interface {
 	Create(ctx context.Context, pr domain.CreatePRRequest) (domain.CreatePRResponse, error)
 	SetMerged(ctx context.Context, prID string) (domain.MergePRResponse, error)
 	Reassign(ctx context.Context, prID, userID string) (domain.ReassignPRResponse, error)
+	Get(ctx context.Context, prID string) (domain.PRResponse, error)
+	List(ctx context.Context, filter domain.PRFilter) (domain.ListPRResponse, error)
+	GetHistory(ctx context.Context, prID string) (domain.PRHistoryResponse, error)
+	Close(ctx context.Context, prID string) (domain.PRResponse, error)
+	Reopen(ctx context.Context, prID string) (domain.PRResponse, error)
+	Ready(ctx context.Context, prID string) (domain.PRResponse, error)
 }
 
+// transitions lists the statuses a PR may move to from its current status.
+var transitions = map[string][]string{
+	domain.StatusDraft:  {domain.StatusOpen, domain.StatusClosed},
+	domain.StatusOpen:   {domain.StatusMerged, domain.StatusClosed},
+	domain.StatusClosed: {domain.StatusOpen},
+	domain.StatusMerged: {},
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range transitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
 type impl struct {
-	repo prrepo.Repository
+	repo      prrepo.Repository
+	selectors Selectors
 }
 
-func NewService(repo prrepo.Repository) Service {
+func NewService(repo prrepo.Repository, selectors Selectors) Service {
 	return &impl{
-		repo: repo,
+		repo:      repo,
+		selectors: selectors,
 	}
 }
 
 func (s *impl) Create(ctx context.Context, pr domain.CreatePRRequest) (domain.CreatePRResponse, error) {
+	ctx, span := tracing.Start(ctx, "pr.Service.Create")
+	defer span.End()
+
 	if err := s.validateCreateRequest(pr); err != nil {
-		slog.Error("PR creation validation failed",
+		slog.ErrorContext(ctx, "PR creation validation failed",
 			"pr_id", pr.PRID,
 			"error", err)
 
 		return domain.CreatePRResponse{}, domain.ErrBadRequest
 	}
 
-	resp, err := s.repo.Create(ctx, pr.PRID, pr.PRName, pr.AuthorID)
+	status := domain.StatusOpen
+	if pr.Draft {
+		status = domain.StatusDraft
+	}
+
+	resp, err := s.repo.Create(ctx, pr.PRID, pr.PRName, pr.AuthorID, pr.TeamName, status, s.selectors.Select)
 	if err != nil {
-		slog.Error("failed to create pull request",
+		if errors.Is(err, domain.ErrNotEnoughReviewers) {
+			metrics.FailedAssignments.WithLabelValues(domain.ReasonPRCreated).Inc()
+		}
+
+		slog.ErrorContext(ctx, "failed to create pull request",
 			"pr_id", pr.PRID,
 			"pr_name", pr.PRName,
 			"author_id", pr.AuthorID,
+			"team_name", pr.TeamName,
 			"error", err)
 
 		return domain.CreatePRResponse{}, err
 	}
 
-	slog.Info("PR created successfully",
+	metrics.PRsCreated.Inc()
+
+	slog.InfoContext(ctx, "PR created successfully",
 		"pr_id", resp.PR.ID,
 		"author", resp.PR.AuthorID,
+		"team", resp.PR.TeamName,
+		"status", resp.PR.Status,
 		"reviewers_count", len(resp.PR.Reviewers),
 	)
 
@@ -56,20 +108,45 @@ func (s *impl) Create(ctx context.Context, pr domain.CreatePRRequest) (domain.Cr
 }
 
 func (s *impl) SetMerged(ctx context.Context, prID string) (domain.MergePRResponse, error) {
+	ctx, span := tracing.Start(ctx, "pr.Service.SetMerged")
+	defer span.End()
+
 	if strings.TrimSpace(prID) == "" {
 		return domain.MergePRResponse{}, domain.ErrBadRequest
 	}
 
+	status, err := s.repo.GetStatus(ctx, prID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get pull request status",
+			"pr_id", prID,
+			"error", err)
+
+		return domain.MergePRResponse{}, err
+	}
+
+	if status != domain.StatusMerged && !canTransition(status, domain.StatusMerged) {
+		slog.ErrorContext(ctx, "illegal PR status transition",
+			"pr_id", prID,
+			"from", status,
+			"to", domain.StatusMerged)
+
+		return domain.MergePRResponse{}, domain.ErrInvalidTransition
+	}
+
 	resp, err := s.repo.SetMerged(ctx, prID)
 	if err != nil {
-		slog.Error("failed to create pull request",
+		slog.ErrorContext(ctx, "failed to merge pull request",
 			"pr_id", prID,
 			"error", err)
 
 		return domain.MergePRResponse{}, err
 	}
 
-	slog.Info("PR merged successfully",
+	if status != domain.StatusMerged {
+		metrics.PRsMerged.Inc()
+	}
+
+	slog.InfoContext(ctx, "PR merged successfully",
 		"pr_id", prID,
 		"merged_at", resp.MergedAt)
 
@@ -77,8 +154,11 @@ func (s *impl) SetMerged(ctx context.Context, prID string) (domain.MergePRRespon
 }
 
 func (s *impl) Reassign(ctx context.Context, prID, userID string) (domain.ReassignPRResponse, error) {
+	ctx, span := tracing.Start(ctx, "pr.Service.Reassign")
+	defer span.End()
+
 	if err := s.validateReassignRequest(prID, userID); err != nil {
-		slog.Error("reassign validation failed",
+		slog.ErrorContext(ctx, "reassign validation failed",
 			"pr_id", prID,
 			"old_reviewer", userID,
 			"error", err)
@@ -86,9 +166,13 @@ func (s *impl) Reassign(ctx context.Context, prID, userID string) (domain.Reassi
 		return domain.ReassignPRResponse{}, domain.ErrBadRequest
 	}
 
-	resp, err := s.repo.Reassign(ctx, prID, userID)
+	resp, err := s.repo.Reassign(ctx, prID, userID, s.selectors.Select)
 	if err != nil {
-		slog.Error("failed to reassign reviewer",
+		if errors.Is(err, domain.ErrNoCandidate) {
+			metrics.FailedAssignments.WithLabelValues(domain.ReasonManualReassign).Inc()
+		}
+
+		slog.ErrorContext(ctx, "failed to reassign reviewer",
 			"pr_id", prID,
 			"old_reviewer", userID,
 			"error", err)
@@ -96,7 +180,9 @@ func (s *impl) Reassign(ctx context.Context, prID, userID string) (domain.Reassi
 		return domain.ReassignPRResponse{}, err
 	}
 
-	slog.Info("reviewer reassigned successfully",
+	metrics.Reassignments.WithLabelValues(domain.ReasonManualReassign).Inc()
+
+	slog.InfoContext(ctx, "reviewer reassigned successfully",
 		"pr_id", prID,
 		"old_reviewer", userID,
 		"new_reviewer", resp.ReplacedBy)
@@ -104,6 +190,165 @@ func (s *impl) Reassign(ctx context.Context, prID, userID string) (domain.Reassi
 	return resp, nil
 }
 
+func (s *impl) Get(ctx context.Context, prID string) (domain.PRResponse, error) {
+	ctx, span := tracing.Start(ctx, "pr.Service.Get")
+	defer span.End()
+
+	if err := s.validatePRID(prID); err != nil {
+		slog.ErrorContext(ctx, "wrong pull request id",
+			"pr_id", prID,
+			"error", err)
+
+		return domain.PRResponse{}, domain.ErrBadRequest
+	}
+
+	pr, err := s.repo.Get(ctx, prID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get pull request",
+			"pr_id", prID,
+			"error", err)
+
+		return domain.PRResponse{}, err
+	}
+
+	return domain.PRResponse{PR: pr}, nil
+}
+
+func (s *impl) List(ctx context.Context, filter domain.PRFilter) (domain.ListPRResponse, error) {
+	ctx, span := tracing.Start(ctx, "pr.Service.List")
+	defer span.End()
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultListLimit
+	}
+
+	if err := s.validateFilter(filter); err != nil {
+		slog.ErrorContext(ctx, "PR list filter validation failed", "error", err)
+		return domain.ListPRResponse{}, domain.ErrBadRequest
+	}
+
+	resp, err := s.repo.List(ctx, filter)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list pull requests", "error", err)
+		return domain.ListPRResponse{}, err
+	}
+
+	slog.InfoContext(ctx, "pull requests listed",
+		"count", len(resp.PullRequests),
+		"has_next", resp.NextCursor != "")
+
+	return resp, nil
+}
+
+func (s *impl) GetHistory(ctx context.Context, prID string) (domain.PRHistoryResponse, error) {
+	ctx, span := tracing.Start(ctx, "pr.Service.GetHistory")
+	defer span.End()
+
+	if err := s.validatePRID(prID); err != nil {
+		slog.ErrorContext(ctx, "wrong pull request id",
+			"pr_id", prID,
+			"error", err)
+
+		return domain.PRHistoryResponse{}, domain.ErrBadRequest
+	}
+
+	resp, err := s.repo.GetHistory(ctx, prID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get assignment history",
+			"pr_id", prID,
+			"error", err)
+
+		return domain.PRHistoryResponse{}, err
+	}
+
+	slog.InfoContext(ctx, "assignment history retrieved",
+		"pr_id", prID,
+		"events_count", len(resp.Events))
+
+	return resp, nil
+}
+
+func (s *impl) Close(ctx context.Context, prID string) (domain.PRResponse, error) {
+	ctx, span := tracing.Start(ctx, "pr.Service.Close")
+	defer span.End()
+
+	return s.transition(ctx, prID, domain.StatusClosed)
+}
+
+func (s *impl) Reopen(ctx context.Context, prID string) (domain.PRResponse, error) {
+	ctx, span := tracing.Start(ctx, "pr.Service.Reopen")
+	defer span.End()
+
+	return s.transition(ctx, prID, domain.StatusOpen, domain.StatusClosed)
+}
+
+func (s *impl) Ready(ctx context.Context, prID string) (domain.PRResponse, error) {
+	ctx, span := tracing.Start(ctx, "pr.Service.Ready")
+	defer span.End()
+
+	return s.transition(ctx, prID, domain.StatusOpen, domain.StatusDraft)
+}
+
+// transition moves the PR to the target status. When allowedFrom is given the
+// current status must be one of them, which keeps e.g. ready from accepting a
+// closed PR. A PR that becomes OPEN without reviewers gets them assigned by the
+// repository, in the same transaction as the status change.
+func (s *impl) transition(ctx context.Context, prID, to string, allowedFrom ...string) (domain.PRResponse, error) {
+	if err := s.validatePRID(prID); err != nil {
+		slog.ErrorContext(ctx, "PR status change validation failed",
+			"pr_id", prID,
+			"error", err)
+
+		return domain.PRResponse{}, domain.ErrBadRequest
+	}
+
+	pr, err := s.repo.Get(ctx, prID)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get pull request",
+			"pr_id", prID,
+			"error", err)
+
+		return domain.PRResponse{}, err
+	}
+
+	if !canTransition(pr.Status, to) || (len(allowedFrom) > 0 && !slices.Contains(allowedFrom, pr.Status)) {
+		slog.ErrorContext(ctx, "illegal PR status transition",
+			"pr_id", prID,
+			"from", pr.Status,
+			"to", to)
+
+		return domain.PRResponse{}, domain.ErrInvalidTransition
+	}
+
+	updated, err := s.repo.SetStatus(ctx, prID, pr.Status, to, s.selectors.Select)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotEnoughReviewers) {
+			reason := domain.ReasonPRReopened
+			if pr.Status == domain.StatusDraft {
+				reason = domain.ReasonPRReady
+			}
+
+			metrics.FailedAssignments.WithLabelValues(reason).Inc()
+		}
+
+		slog.ErrorContext(ctx, "failed to change pull request status",
+			"pr_id", prID,
+			"from", pr.Status,
+			"to", to,
+			"error", err)
+
+		return domain.PRResponse{}, err
+	}
+
+	slog.InfoContext(ctx, "PR status changed",
+		"pr_id", prID,
+		"from", pr.Status,
+		"to", updated.Status,
+		"reviewers_count", len(updated.Reviewers))
+
+	return domain.PRResponse{PR: updated}, nil
+}
+
 func (s *impl) validateCreateRequest(req domain.CreatePRRequest) error {
 	if strings.TrimSpace(req.PRID) == "" {
 		return fmt.Errorf("PR ID is required")
@@ -121,6 +366,34 @@ func (s *impl) validateCreateRequest(req domain.CreatePRRequest) error {
 	return nil
 }
 
+func (s *impl) validateFilter(filter domain.PRFilter) error {
+	if filter.Limit < 1 || filter.Limit > maxListLimit {
+		return fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+	}
+	if _, ok := transitions[filter.Status]; filter.Status != "" && !ok {
+		return fmt.Errorf("unknown status: %s", filter.Status)
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return fmt.Errorf("created_from must be before created_to")
+	}
+	if filter.MergedFrom != nil && filter.MergedTo != nil && !filter.MergedFrom.Before(*filter.MergedTo) {
+		return fmt.Errorf("merged_from must be before merged_to")
+	}
+
+	return nil
+}
+
+func (s *impl) validatePRID(prID string) error {
+	if strings.TrimSpace(prID) == "" {
+		return fmt.Errorf("PR ID is required")
+	}
+	if !strings.HasPrefix(prID, "pr-") {
+		return fmt.Errorf("PR ID must start with 'pr-'")
+	}
+
+	return nil
+}
+
 func (s *impl) validateReassignRequest(prID, userID string) error {
 	if strings.TrimSpace(prID) == "" {
 		return fmt.Errorf("PR ID is required")
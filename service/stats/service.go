@@ -0,0 +1,51 @@
+package stats
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	statsrepo "github.com/dafuqqqyunglean/avito_tech/database/stats"
+	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
+)
+
+type Service interface {
+	GetAssignmentStats(ctx context.Context, from, to *time.Time) (domain.AssignmentStatsResponse, error)
+}
+
+type impl struct {
+	repo statsrepo.Repository
+}
+
+func NewService(repo statsrepo.Repository) Service {
+	return &impl{
+		repo: repo,
+	}
+}
+
+func (s *impl) GetAssignmentStats(ctx context.Context, from, to *time.Time) (domain.AssignmentStatsResponse, error) {
+	ctx, span := tracing.Start(ctx, "stats.Service.GetAssignmentStats")
+	defer span.End()
+
+	if from != nil && to != nil && !from.Before(*to) {
+		slog.ErrorContext(ctx, "wrong stats time window", "from", from, "to", to)
+		return domain.AssignmentStatsResponse{}, domain.ErrBadRequest
+	}
+
+	resp, err := s.repo.GetAssignmentStats(ctx, from, to)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get assignment stats",
+			"from", from,
+			"to", to,
+			"error", err)
+
+		return domain.AssignmentStatsResponse{}, err
+	}
+
+	slog.InfoContext(ctx, "assignment stats retrieved",
+		"users_count", len(resp.Users),
+		"teams_count", len(resp.Teams))
+
+	return resp, nil
+}
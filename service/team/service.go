@@ -8,44 +8,66 @@ import (
 
 	teamrepo "github.com/dafuqqqyunglean/avito_tech/database/team"
 	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/metrics"
+	prserv "github.com/dafuqqqyunglean/avito_tech/service/pr"
 	"github.com/dafuqqqyunglean/avito_tech/service/team/mapper"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
 )
 
 type Service interface {
 	CreateTeam(ctx context.Context, req domain.TeamRequest) (domain.TeamResponse, error)
 	GetTeam(ctx context.Context, teamName string) (domain.TeamRequest, error)
+	GetSettings(ctx context.Context, teamName string) (domain.TeamSettingsResponse, error)
+	UpdateSettings(ctx context.Context, req domain.TeamSettingsRequest) (domain.TeamSettingsResponse, error)
+	AddMembers(ctx context.Context, req domain.AddMembersRequest) (domain.TeamResponse, error)
+	RemoveMembers(ctx context.Context, req domain.RemoveMembersRequest) (domain.TeamResponse, error)
+	MoveMember(ctx context.Context, req domain.MoveMemberRequest) (domain.TeamResponse, error)
+	ListTeams(ctx context.Context) (domain.ListTeamsResponse, error)
+	RenameTeam(ctx context.Context, req domain.RenameTeamRequest) (domain.TeamResponse, error)
+	DeleteTeam(ctx context.Context, req domain.DeleteTeamRequest) (domain.DeleteTeamResponse, error)
 }
 
+const maxReviewersLimit = 10
+
 type impl struct {
-	repo teamrepo.Repository
+	repo      teamrepo.Repository
+	selectors prserv.Selectors
 }
 
-func NewService(repo teamrepo.Repository) Service {
+func NewService(repo teamrepo.Repository, selectors prserv.Selectors) Service {
 	return &impl{
-		repo: repo,
+		repo:      repo,
+		selectors: selectors,
 	}
 }
 
 func (s *impl) CreateTeam(ctx context.Context, req domain.TeamRequest) (domain.TeamResponse, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.CreateTeam")
+	defer span.End()
+
 	err := s.validateTeam(req)
 	if err != nil {
-		slog.Error("team validation failed",
+		slog.ErrorContext(ctx, "team validation failed",
 			"team_name", req.TeamName,
 			"error", err)
 
 		return domain.TeamResponse{}, domain.ErrBadRequest
 	}
 
-	res, err := s.repo.CreateTeam(ctx, req.TeamName, req.Members)
+	if req.AssignmentStrategy == "" {
+		req.AssignmentStrategy = domain.StrategyLeastLoaded
+	}
+
+	res, err := s.repo.CreateTeam(ctx, req.TeamName, req.AssignmentStrategy, req.Members)
 	if err != nil {
-		slog.Error("failed to create team",
+		slog.ErrorContext(ctx, "failed to create team",
 			"error", err,
 			"team_name", req.TeamName)
 
 		return domain.TeamResponse{}, err
 	}
 
-	slog.Info("team created successfully",
+	slog.InfoContext(ctx, "team created successfully",
 		"team_name", res.TeamName,
 		"members_count", len(res.Members))
 
@@ -53,25 +75,317 @@ func (s *impl) CreateTeam(ctx context.Context, req domain.TeamRequest) (domain.T
 }
 
 func (s *impl) GetTeam(ctx context.Context, teamName string) (domain.TeamRequest, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.GetTeam")
+	defer span.End()
+
 	if strings.TrimSpace(teamName) == "" {
-		slog.Error("wrong team name", "team_name", teamName)
+		slog.ErrorContext(ctx, "wrong team name", "team_name", teamName)
 		return domain.TeamRequest{}, domain.ErrBadRequest
 	}
 
 	res, err := s.repo.GetTeam(ctx, teamName)
 	if err != nil {
-		slog.Error("failed to get team",
+		slog.ErrorContext(ctx, "failed to get team",
 			"error", err,
 			"team_name", teamName)
 
 		return domain.TeamRequest{}, err
 	}
 
-	slog.Info("team retrieved successfully", "team_name", teamName, "members_count", len(res.Members))
+	slog.InfoContext(ctx, "team retrieved successfully", "team_name", teamName, "members_count", len(res.Members))
 
 	return res, nil
 }
 
+func (s *impl) GetSettings(ctx context.Context, teamName string) (domain.TeamSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.GetSettings")
+	defer span.End()
+
+	if strings.TrimSpace(teamName) == "" {
+		slog.ErrorContext(ctx, "wrong team name", "team_name", teamName)
+		return domain.TeamSettingsResponse{}, domain.ErrBadRequest
+	}
+
+	settings, err := s.repo.GetSettings(ctx, teamName)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get team settings",
+			"error", err,
+			"team_name", teamName)
+
+		return domain.TeamSettingsResponse{}, err
+	}
+
+	return domain.TeamSettingsResponse{Settings: settings}, nil
+}
+
+func (s *impl) UpdateSettings(ctx context.Context, req domain.TeamSettingsRequest) (domain.TeamSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.UpdateSettings")
+	defer span.End()
+
+	if strings.TrimSpace(req.TeamName) == "" {
+		slog.ErrorContext(ctx, "wrong team name", "team_name", req.TeamName)
+		return domain.TeamSettingsResponse{}, domain.ErrBadRequest
+	}
+
+	settings, err := s.repo.GetSettings(ctx, req.TeamName)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to get team settings",
+			"error", err,
+			"team_name", req.TeamName)
+
+		return domain.TeamSettingsResponse{}, err
+	}
+
+	if req.AssignmentStrategy != "" {
+		settings.AssignmentStrategy = req.AssignmentStrategy
+	}
+	if req.MinReviewers != nil {
+		settings.MinReviewers = *req.MinReviewers
+	}
+	if req.MaxReviewers != nil {
+		settings.MaxReviewers = *req.MaxReviewers
+	}
+
+	if err := s.validateSettings(settings); err != nil {
+		slog.ErrorContext(ctx, "team settings validation failed",
+			"team_name", req.TeamName,
+			"error", err)
+
+		return domain.TeamSettingsResponse{}, domain.ErrBadRequest
+	}
+
+	updated, err := s.repo.UpdateSettings(ctx, settings)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update team settings",
+			"error", err,
+			"team_name", req.TeamName)
+
+		return domain.TeamSettingsResponse{}, err
+	}
+
+	slog.InfoContext(ctx, "team settings updated",
+		"team_name", updated.TeamName,
+		"assignment_strategy", updated.AssignmentStrategy,
+		"min_reviewers", updated.MinReviewers,
+		"max_reviewers", updated.MaxReviewers)
+
+	return domain.TeamSettingsResponse{Settings: updated}, nil
+}
+
+func (s *impl) AddMembers(ctx context.Context, req domain.AddMembersRequest) (domain.TeamResponse, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.AddMembers")
+	defer span.End()
+
+	err := s.validateAddMembers(req)
+	if err != nil {
+		slog.ErrorContext(ctx, "wrong request format", "error", err)
+		return domain.TeamResponse{}, domain.ErrBadRequest
+	}
+
+	team, err := s.repo.AddMembers(ctx, req.TeamName, req.Members)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to add team members",
+			"error", err,
+			"team_name", req.TeamName)
+
+		return domain.TeamResponse{}, err
+	}
+
+	slog.InfoContext(ctx, "team members added",
+		"team_name", team.TeamName,
+		"added_count", len(req.Members),
+		"members_count", len(team.Members))
+
+	return mapper.FromReqToResp(team), nil
+}
+
+func (s *impl) RemoveMembers(ctx context.Context, req domain.RemoveMembersRequest) (domain.TeamResponse, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.RemoveMembers")
+	defer span.End()
+
+	err := s.validateRemoveMembers(req)
+	if err != nil {
+		slog.ErrorContext(ctx, "wrong request format", "error", err)
+		return domain.TeamResponse{}, domain.ErrBadRequest
+	}
+
+	team, reassignments, err := s.repo.RemoveMembers(ctx, req.TeamName, req.UserIDs, s.selectors.Select)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to remove team members",
+			"error", err,
+			"team_name", req.TeamName,
+			"user_ids", req.UserIDs)
+
+		return domain.TeamResponse{}, err
+	}
+
+	logReassignments(ctx, domain.ReasonMemberRemoved, reassignments)
+
+	slog.InfoContext(ctx, "team members removed",
+		"team_name", team.TeamName,
+		"removed_count", len(req.UserIDs),
+		"members_count", len(team.Members),
+		"reassigned_reviews", len(reassignments))
+
+	return mapper.FromReqToResp(team), nil
+}
+
+func (s *impl) MoveMember(ctx context.Context, req domain.MoveMemberRequest) (domain.TeamResponse, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.MoveMember")
+	defer span.End()
+
+	err := s.validateMoveMember(req)
+	if err != nil {
+		slog.ErrorContext(ctx, "wrong request format", "error", err)
+		return domain.TeamResponse{}, domain.ErrBadRequest
+	}
+
+	team, reassignments, err := s.repo.MoveMember(ctx, req.UserID, req.FromTeam, req.ToTeam, s.selectors.Select)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to move team member",
+			"error", err,
+			"user_id", req.UserID,
+			"from_team", req.FromTeam,
+			"to_team", req.ToTeam)
+
+		return domain.TeamResponse{}, err
+	}
+
+	logReassignments(ctx, domain.ReasonMemberMoved, reassignments)
+
+	slog.InfoContext(ctx, "team member moved",
+		"user_id", req.UserID,
+		"from_team", req.FromTeam,
+		"to_team", team.TeamName,
+		"reassigned_reviews", len(reassignments))
+
+	return mapper.FromReqToResp(team), nil
+}
+
+func (s *impl) ListTeams(ctx context.Context) (domain.ListTeamsResponse, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.ListTeams")
+	defer span.End()
+
+	teams, err := s.repo.ListTeams(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list teams", "error", err)
+		return domain.ListTeamsResponse{}, err
+	}
+
+	return domain.ListTeamsResponse{Teams: teams}, nil
+}
+
+func (s *impl) RenameTeam(ctx context.Context, req domain.RenameTeamRequest) (domain.TeamResponse, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.RenameTeam")
+	defer span.End()
+
+	err := s.validateRenameTeam(req)
+	if err != nil {
+		slog.ErrorContext(ctx, "wrong request format", "error", err)
+		return domain.TeamResponse{}, domain.ErrBadRequest
+	}
+
+	team, err := s.repo.RenameTeam(ctx, req.TeamName, req.NewName)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to rename team",
+			"error", err,
+			"team_name", req.TeamName,
+			"new_name", req.NewName)
+
+		return domain.TeamResponse{}, err
+	}
+
+	slog.InfoContext(ctx, "team renamed",
+		"old_name", req.TeamName,
+		"team_name", team.TeamName)
+
+	return mapper.FromReqToResp(team), nil
+}
+
+func (s *impl) DeleteTeam(ctx context.Context, req domain.DeleteTeamRequest) (domain.DeleteTeamResponse, error) {
+	ctx, span := tracing.Start(ctx, "team.Service.DeleteTeam")
+	defer span.End()
+
+	err := s.validateDeleteTeam(req)
+	if err != nil {
+		slog.ErrorContext(ctx, "wrong request format", "error", err)
+		return domain.DeleteTeamResponse{}, domain.ErrBadRequest
+	}
+
+	resp, err := s.repo.DeleteTeam(ctx, req.TeamName, req.Force, req.ReassignTo, s.selectors.Select)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete team",
+			"error", err,
+			"team_name", req.TeamName,
+			"force", req.Force,
+			"reassign_to", req.ReassignTo)
+
+		return domain.DeleteTeamResponse{}, err
+	}
+
+	logReassignments(ctx, domain.ReasonTeamDeleted, resp.Reassignments)
+
+	slog.InfoContext(ctx, "team deleted",
+		"team_name", resp.TeamName,
+		"closed_prs", len(resp.ClosedPRs),
+		"reassigned_prs", len(resp.ReassignedPRs),
+		"reassigned_to", resp.ReassignedTo,
+		"reassigned_reviews", len(resp.Reassignments))
+
+	return resp, nil
+}
+
+// logReassignments reports the reviews released by members leaving a team.
+func logReassignments(ctx context.Context, reason string, reassignments []domain.Reassignment) {
+	for _, reassignment := range reassignments {
+		if reassignment.NewReviewerID == "" {
+			metrics.FailedAssignments.WithLabelValues(reason).Inc()
+
+			slog.WarnContext(ctx, "no replacement reviewer available",
+				"pr_id", reassignment.PrID,
+				"old_reviewer", reassignment.OldReviewerID)
+
+			continue
+		}
+
+		metrics.Reassignments.WithLabelValues(reason).Inc()
+
+		slog.InfoContext(ctx, "review reassigned from leaving member",
+			"pr_id", reassignment.PrID,
+			"old_reviewer", reassignment.OldReviewerID,
+			"new_reviewer", reassignment.NewReviewerID)
+	}
+}
+
+func (s *impl) validateSettings(settings domain.TeamSettings) error {
+	if err := validateStrategy(settings.AssignmentStrategy); err != nil {
+		return err
+	}
+
+	if settings.MinReviewers < 0 {
+		return fmt.Errorf("min_reviewers must not be negative")
+	}
+
+	if settings.MaxReviewers < 1 || settings.MaxReviewers > maxReviewersLimit {
+		return fmt.Errorf("max_reviewers must be between 1 and %d", maxReviewersLimit)
+	}
+
+	if settings.MinReviewers > settings.MaxReviewers {
+		return fmt.Errorf("min_reviewers must not exceed max_reviewers")
+	}
+
+	return nil
+}
+
+func validateStrategy(strategy string) error {
+	switch strategy {
+	case "", domain.StrategyRandom, domain.StrategyRoundRobin, domain.StrategyLeastLoaded:
+		return nil
+	default:
+		return fmt.Errorf("unknown assignment strategy: %s", strategy)
+	}
+}
+
 func (s *impl) validateTeam(team domain.TeamRequest) error {
 	if strings.TrimSpace(team.TeamName) == "" {
 		return fmt.Errorf("team name is required")
@@ -85,8 +399,99 @@ func (s *impl) validateTeam(team domain.TeamRequest) error {
 		return fmt.Errorf("team name too long")
 	}
 
+	if err := validateStrategy(team.AssignmentStrategy); err != nil {
+		return err
+	}
+
+	return validateMembers(team.Members)
+}
+
+func (s *impl) validateAddMembers(req domain.AddMembersRequest) error {
+	if strings.TrimSpace(req.TeamName) == "" {
+		return fmt.Errorf("team name is required")
+	}
+
+	if len(req.Members) == 0 {
+		return fmt.Errorf("members are required")
+	}
+
+	return validateMembers(req.Members)
+}
+
+func (s *impl) validateRemoveMembers(req domain.RemoveMembersRequest) error {
+	if strings.TrimSpace(req.TeamName) == "" {
+		return fmt.Errorf("team name is required")
+	}
+
+	if len(req.UserIDs) == 0 {
+		return fmt.Errorf("user_ids are required")
+	}
+
+	seenUsers := make(map[string]bool)
+	for i, userID := range req.UserIDs {
+		if strings.TrimSpace(userID) == "" {
+			return fmt.Errorf("user_ids[%d] is empty", i)
+		}
+		if seenUsers[userID] {
+			return fmt.Errorf("duplicate user_id: %s", userID)
+		}
+		seenUsers[userID] = true
+	}
+
+	return nil
+}
+
+func (s *impl) validateMoveMember(req domain.MoveMemberRequest) error {
+	if strings.TrimSpace(req.UserID) == "" {
+		return fmt.Errorf("user_id is required")
+	}
+
+	if strings.TrimSpace(req.FromTeam) == "" || strings.TrimSpace(req.ToTeam) == "" {
+		return fmt.Errorf("from_team and to_team are required")
+	}
+
+	if req.FromTeam == req.ToTeam {
+		return fmt.Errorf("from_team and to_team must differ")
+	}
+
+	return nil
+}
+
+func (s *impl) validateRenameTeam(req domain.RenameTeamRequest) error {
+	if strings.TrimSpace(req.TeamName) == "" || strings.TrimSpace(req.NewName) == "" {
+		return fmt.Errorf("team_name and new_name are required")
+	}
+
+	if len(req.NewName) > 100 {
+		return fmt.Errorf("team name too long")
+	}
+
+	if req.TeamName == req.NewName {
+		return fmt.Errorf("team_name and new_name must differ")
+	}
+
+	return nil
+}
+
+func (s *impl) validateDeleteTeam(req domain.DeleteTeamRequest) error {
+	if strings.TrimSpace(req.TeamName) == "" {
+		return fmt.Errorf("team name is required")
+	}
+
+	if req.ReassignTo != "" && !req.Force {
+		return fmt.Errorf("reassign_to requires force")
+	}
+
+	if req.ReassignTo == req.TeamName {
+		return fmt.Errorf("reassign_to must differ from team_name")
+	}
+
+	return nil
+}
+
+func validateMembers(members []domain.User) error {
 	seenUsers := make(map[string]bool)
-	for i, member := range team.Members {
+	for i, member := range members {
 		if strings.TrimSpace(member.ID) == "" {
 			return fmt.Errorf("member %d: user_id is required", i)
 		}
@@ -8,32 +8,41 @@ import (
 
 	userrepo "github.com/dafuqqqyunglean/avito_tech/database/user"
 	"github.com/dafuqqqyunglean/avito_tech/domain"
+	"github.com/dafuqqqyunglean/avito_tech/metrics"
+	prserv "github.com/dafuqqqyunglean/avito_tech/service/pr"
+	"github.com/dafuqqqyunglean/avito_tech/tracing"
 )
 
 type Service interface {
 	SetActive(ctx context.Context, req domain.SetActiveRequest) (domain.SetActiveResponse, error)
 	GetReview(ctx context.Context, userID string) (domain.GetReviewResponse, error)
+	DeactivateTeamMembers(ctx context.Context, req domain.DeactivateUsersRequest) (domain.DeactivateUsersResponse, error)
 }
 
 type impl struct {
-	repo userrepo.Repository
+	repo      userrepo.Repository
+	selectors prserv.Selectors
 }
 
-func NewService(repo userrepo.Repository) Service {
+func NewService(repo userrepo.Repository, selectors prserv.Selectors) Service {
 	return &impl{
-		repo: repo,
+		repo:      repo,
+		selectors: selectors,
 	}
 }
 
 func (s *impl) SetActive(ctx context.Context, req domain.SetActiveRequest) (domain.SetActiveResponse, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.SetActive")
+	defer span.End()
+
 	if err := s.validateSetActiveRequest(req); err != nil {
-		slog.Error("wrong request format", "error", err)
+		slog.ErrorContext(ctx, "wrong request format", "error", err)
 		return domain.SetActiveResponse{}, domain.ErrBadRequest
 	}
 
-	user, err := s.repo.SetActive(ctx, req.UserID, req.IsActive)
+	user, err := s.repo.SetActive(ctx, req.UserID, req.IsActive, s.selectors.Select)
 	if err != nil {
-		slog.Error("failed to set user active status",
+		slog.ErrorContext(ctx, "failed to set user active status",
 			"user_id", req.UserID,
 			"is_active", req.IsActive,
 			"error", err)
@@ -41,37 +50,104 @@ func (s *impl) SetActive(ctx context.Context, req domain.SetActiveRequest) (doma
 		return domain.SetActiveResponse{}, err
 	}
 
-	slog.Info("user active status updated",
+	slog.InfoContext(ctx, "user active status updated",
 		"user_id", user.User.UserID,
 		"username", user.User.Username,
 		"is_active", user.User.IsActive,
-		"team", user.User.TeamName)
+		"teams", user.User.Teams,
+		"reassigned_reviews", len(user.Reassignments))
+
+	for _, reassignment := range user.Reassignments {
+		recordReassignment(domain.ReasonUserDeactivated, reassignment)
+
+		if reassignment.NewReviewerID == "" {
+			slog.WarnContext(ctx, "no replacement reviewer available",
+				"pr_id", reassignment.PrID,
+				"old_reviewer", reassignment.OldReviewerID)
+
+			continue
+		}
+
+		slog.InfoContext(ctx, "review reassigned from deactivated user",
+			"pr_id", reassignment.PrID,
+			"old_reviewer", reassignment.OldReviewerID,
+			"new_reviewer", reassignment.NewReviewerID)
+	}
 
 	return user, nil
 }
 
+func (s *impl) DeactivateTeamMembers(ctx context.Context, req domain.DeactivateUsersRequest) (domain.DeactivateUsersResponse, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.DeactivateTeamMembers")
+	defer span.End()
+
+	if err := s.validateDeactivateRequest(req); err != nil {
+		slog.ErrorContext(ctx, "wrong request format", "error", err)
+		return domain.DeactivateUsersResponse{}, domain.ErrBadRequest
+	}
+
+	resp, err := s.repo.DeactivateTeamMembers(ctx, req.TeamName, req.UserIDs, s.selectors.Select)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to deactivate team members",
+			"team_name", req.TeamName,
+			"user_ids", req.UserIDs,
+			"error", err)
+
+		return domain.DeactivateUsersResponse{}, err
+	}
+
+	for _, reassignment := range resp.Reassignments {
+		recordReassignment(domain.ReasonTeamDeactivation, reassignment)
+	}
+
+	if len(resp.UnderstaffedPRs) > 0 {
+		slog.WarnContext(ctx, "pull requests left without replacement reviewers",
+			"team_name", resp.TeamName,
+			"pr_ids", resp.UnderstaffedPRs)
+	}
+
+	slog.InfoContext(ctx, "team members deactivated",
+		"team_name", resp.TeamName,
+		"deactivated_count", len(resp.DeactivatedUsers),
+		"reassigned_reviews", len(resp.Reassignments))
+
+	return resp, nil
+}
+
 func (s *impl) GetReview(ctx context.Context, userID string) (domain.GetReviewResponse, error) {
+	ctx, span := tracing.Start(ctx, "user.Service.GetReview")
+	defer span.End()
+
 	if strings.TrimSpace(userID) == "" {
-		slog.Error("wrong user id", "user_id", userID)
+		slog.ErrorContext(ctx, "wrong user id", "user_id", userID)
 		return domain.GetReviewResponse{}, domain.ErrBadRequest
 	}
 
 	resp, err := s.repo.GetReview(ctx, userID)
 	if err != nil {
-		slog.Error("failed to get user pull requests",
+		slog.ErrorContext(ctx, "failed to get user pull requests",
 			"user_id", userID,
 			"error", err)
 
 		return domain.GetReviewResponse{}, err
 	}
 
-	slog.Info("user reviews retrieved",
+	slog.InfoContext(ctx, "user reviews retrieved",
 		"user_id", userID,
 		"pr_count", len(resp.PullRequests))
 
 	return resp, nil
 }
 
+func recordReassignment(reason string, reassignment domain.Reassignment) {
+	if reassignment.NewReviewerID == "" {
+		metrics.FailedAssignments.WithLabelValues(reason).Inc()
+		return
+	}
+
+	metrics.Reassignments.WithLabelValues(reason).Inc()
+}
+
 func (s *impl) validateSetActiveRequest(req domain.SetActiveRequest) error {
 	if strings.TrimSpace(req.UserID) == "" {
 		return fmt.Errorf("user_id is required")
@@ -83,3 +159,26 @@ func (s *impl) validateSetActiveRequest(req domain.SetActiveRequest) error {
 
 	return nil
 }
+
+func (s *impl) validateDeactivateRequest(req domain.DeactivateUsersRequest) error {
+	if strings.TrimSpace(req.TeamName) == "" {
+		return fmt.Errorf("team_name is required")
+	}
+
+	if len(req.UserIDs) == 0 {
+		return fmt.Errorf("user_ids must not be empty")
+	}
+
+	seenUsers := make(map[string]bool)
+	for i, userID := range req.UserIDs {
+		if !strings.HasPrefix(userID, "u") {
+			return fmt.Errorf("user %d: invalid user_id format", i)
+		}
+		if seenUsers[userID] {
+			return fmt.Errorf("duplicate user_id: %s", userID)
+		}
+		seenUsers[userID] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryNames maps the text of every registered query to its SQL file name.
+var queryNames sync.Map
+
+// MustRegisterQueries names each *.sql file in fsys after its file, so spans of
+// embedded queries are called e.g. "getUserTeam" instead of carrying raw SQL.
+// Names are looked up by query text, so it panics when a text is already
+// registered under another name: the span would otherwise be named after
+// whichever package happened to register first.
+func MustRegisterQueries(fsys fs.FS) {
+	err := fs.WalkDir(fsys, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || path.Ext(filePath) != ".sql" {
+			return err
+		}
+
+		query, err := fs.ReadFile(fsys, filePath)
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(path.Base(filePath), ".sql")
+		if registered, loaded := queryNames.LoadOrStore(string(query), name); loaded && registered != name {
+			return fmt.Errorf("%s has the same text as %s", filePath, registered)
+		}
+
+		return nil
+	})
+	if err != nil {
+		panic(fmt.Sprintf("failed to register queries: %v", err))
+	}
+}
+
+// QueryName returns the SQL file name of a registered query. Anything else,
+// such as the BEGIN and COMMIT statements issued by pgx, is named after its
+// first keyword.
+func QueryName(sql string) string {
+	if name, ok := queryNames.Load(sql); ok {
+		return name.(string)
+	}
+
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToLower(fields[0])
+	}
+
+	return "query"
+}
+
+// QueryTracer is a pgx.QueryTracer opening one client span per query.
+type QueryTracer struct{}
+
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{}
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx, _ = Start(ctx, QueryName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.String("db.query.text", data.SQL),
+		))
+
+	return ctx
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+}
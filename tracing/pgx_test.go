@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracerNamesSpansAfterSQLFiles(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	const getTeam = "SELECT team_name FROM teams WHERE team_name = $1;\n"
+	MustRegisterQueries(fstest.MapFS{
+		"sql/getTestTeam.sql": &fstest.MapFile{Data: []byte(getTeam)},
+		"sql/README.md":       &fstest.MapFile{Data: []byte("not a query")},
+	})
+
+	tracer := NewQueryTracer()
+	queryErr := errors.New("boom")
+
+	for _, query := range []struct {
+		sql string
+		err error
+	}{
+		{sql: getTeam},
+		{sql: "commit"},
+		{sql: "SELECT 1", err: queryErr},
+	} {
+		ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: query.sql})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1"), Err: query.err})
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+
+	for i, want := range []string{"getTestTeam", "commit", "select"} {
+		if spans[i].Name != want {
+			t.Errorf("span %d: got name %q, want %q", i, spans[i].Name, want)
+		}
+	}
+
+	if spans[2].Status.Code != codes.Error {
+		t.Errorf("failed query span: got status %v, want error", spans[2].Status.Code)
+	}
+}
+
+func TestMustRegisterQueriesRejectsDuplicateText(t *testing.T) {
+	const query = "SELECT id FROM duplicated WHERE id = $1;\n"
+	MustRegisterQueries(fstest.MapFS{
+		"sql/getDuplicated.sql": &fstest.MapFile{Data: []byte(query)},
+	})
+
+	// The same file registered again keeps its name.
+	MustRegisterQueries(fstest.MapFS{
+		"sql/getDuplicated.sql": &fstest.MapFile{Data: []byte(query)},
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering the same text under another name did not panic")
+		}
+
+		if name := QueryName(query); name != "getDuplicated" {
+			t.Errorf("got name %q, want %q", name, "getDuplicated")
+		}
+	}()
+
+	MustRegisterQueries(fstest.MapFS{
+		"sql/lookupDuplicated.sql": &fstest.MapFile{Data: []byte(query)},
+	})
+}